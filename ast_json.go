@@ -0,0 +1,726 @@
+package lox
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+)
+
+// Every node marshals to a JSON object whose "type" field names its Go type,
+// alongside one field per child or token, and an "origin" field if a rewrite
+// gave it one. UnmarshalExpr, UnmarshalStmt and UnmarshalProgram read them
+// back, using "type" to choose what to build.
+
+// UnmarshalExpr decodes an expression written by json.Marshal.
+func UnmarshalExpr(data []byte) (Expr, error) {
+	var e exprJSON
+	if err := json.Unmarshal(data, &e); err != nil {
+		return nil, err
+	}
+	return e.Expr, nil
+}
+
+// UnmarshalStmt decodes a statement written by json.Marshal.
+func UnmarshalStmt(data []byte) (Stmt, error) {
+	var s stmtJSON
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, err
+	}
+	return s.Stmt, nil
+}
+
+// UnmarshalProgram decodes a JSON array of statements, such as json.Marshal
+// writes for the result of ParseProgram.
+func UnmarshalProgram(data []byte) ([]Stmt, error) {
+	var statements []stmtJSON
+	if err := json.Unmarshal(data, &statements); err != nil {
+		return nil, err
+	}
+	return stmts(statements), nil
+}
+
+// nodeDecoders builds each kind of node from its JSON object.
+var nodeDecoders = map[string]func(data []byte) (Node, error){
+	"Binary":         decodeNode[Binary],
+	"Unary":          decodeNode[Unary],
+	"Group":          decodeNode[Group],
+	"Literal":        decodeNode[Literal],
+	"Variable":       decodeNode[Variable],
+	"Call":           decodeNode[Call],
+	"Assign":         decodeNode[Assign],
+	"ListLiteral":    decodeNode[ListLiteral],
+	"MapLiteral":     decodeNode[MapLiteral],
+	"Index":          decodeNode[Index],
+	"SetIndex":       decodeNode[SetIndex],
+	"Get":            decodeNode[Get],
+	"ExpressionStmt": decodeNode[ExpressionStmt],
+	"PrintStmt":      decodeNode[PrintStmt],
+	"VarStmt":        decodeNode[VarStmt],
+	"BlockStmt":      decodeNode[BlockStmt],
+	"ForInStmt":      decodeNode[ForInStmt],
+	"IfStmt":         decodeNode[IfStmt],
+	"ThrowStmt":      decodeNode[ThrowStmt],
+	"TryStmt":        decodeNode[TryStmt],
+}
+
+func decodeNode[N Node, P interface {
+	*N
+	json.Unmarshaler
+}](data []byte) (Node, error) {
+	var n N
+	if err := P(&n).UnmarshalJSON(data); err != nil {
+		return nil, err
+	}
+	return n, nil
+}
+
+func unmarshalNode(data []byte) (Node, error) {
+	var header struct {
+		Type string `json:"type"`
+	}
+	if err := json.Unmarshal(data, &header); err != nil {
+		return nil, err
+	}
+	decode, ok := nodeDecoders[header.Type]
+	if !ok {
+		return nil, fmt.Errorf("unknown node type %q", header.Type)
+	}
+	return decode(data)
+}
+
+// exprJSON decodes whichever expression its JSON object describes, or nil
+// for null.
+type exprJSON struct {
+	Expr
+}
+
+func (e *exprJSON) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		e.Expr = nil
+		return nil
+	}
+	n, err := unmarshalNode(data)
+	if err != nil {
+		return err
+	}
+	expr, ok := n.(Expr)
+	if !ok {
+		return fmt.Errorf("expected an expression, got %T", n)
+	}
+	e.Expr = expr
+	return nil
+}
+
+// stmtJSON decodes whichever statement its JSON object describes, or nil for
+// null.
+type stmtJSON struct {
+	Stmt
+}
+
+func (s *stmtJSON) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		s.Stmt = nil
+		return nil
+	}
+	n, err := unmarshalNode(data)
+	if err != nil {
+		return err
+	}
+	stmt, ok := n.(Stmt)
+	if !ok {
+		return fmt.Errorf("expected a statement, got %T", n)
+	}
+	s.Stmt = stmt
+	return nil
+}
+
+// exprs and stmts unwrap decoded lists, keeping the difference between a
+// missing list (null) and an empty one.
+func exprs(decoded []exprJSON) []Expr {
+	if decoded == nil {
+		return nil
+	}
+	result := make([]Expr, len(decoded))
+	for idx, e := range decoded {
+		result[idx] = e.Expr
+	}
+	return result
+}
+
+func stmts(decoded []stmtJSON) []Stmt {
+	if decoded == nil {
+		return nil
+	}
+	result := make([]Stmt, len(decoded))
+	for idx, s := range decoded {
+		result[idx] = s.Stmt
+	}
+	return result
+}
+
+// nodeHeader holds the fields every node's JSON object has.
+type nodeHeader struct {
+	Type   string  `json:"type"`
+	Origin *Origin `json:"origin,omitempty"`
+}
+
+func header(typ string, o origin) nodeHeader {
+	return nodeHeader{Type: typ, Origin: o.from}
+}
+
+func (h nodeHeader) origin() origin {
+	return origin{from: h.Origin}
+}
+
+func (b Binary) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		nodeHeader
+		Left     Expr  `json:"left"`
+		Operator Token `json:"operator"`
+		Right    Expr  `json:"right"`
+	}{header("Binary", b.origin), b.left, b.operator, b.right})
+}
+
+func (b *Binary) UnmarshalJSON(data []byte) error {
+	var v struct {
+		nodeHeader
+		Left     exprJSON `json:"left"`
+		Operator Token    `json:"operator"`
+		Right    exprJSON `json:"right"`
+	}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	*b = Binary{left: v.Left.Expr, operator: v.Operator, right: v.Right.Expr, origin: v.origin()}
+	return nil
+}
+
+func (u Unary) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		nodeHeader
+		Operator Token `json:"operator"`
+		Right    Expr  `json:"right"`
+	}{header("Unary", u.origin), u.operator, u.right})
+}
+
+func (u *Unary) UnmarshalJSON(data []byte) error {
+	var v struct {
+		nodeHeader
+		Operator Token    `json:"operator"`
+		Right    exprJSON `json:"right"`
+	}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	*u = Unary{operator: v.Operator, right: v.Right.Expr, origin: v.origin()}
+	return nil
+}
+
+func (g Group) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		nodeHeader
+		Expression Expr `json:"expression"`
+	}{header("Group", g.origin), g.expr})
+}
+
+func (g *Group) UnmarshalJSON(data []byte) error {
+	var v struct {
+		nodeHeader
+		Expression exprJSON `json:"expression"`
+	}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	*g = Group{expr: v.Expression.Expr, origin: v.origin()}
+	return nil
+}
+
+func (l Literal) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		nodeHeader
+		Value any   `json:"value"`
+		Token Token `json:"token"`
+	}{header("Literal", l.origin), jsonValue(l.literal), l.token})
+}
+
+func (l *Literal) UnmarshalJSON(data []byte) error {
+	var v struct {
+		nodeHeader
+		Value json.RawMessage `json:"value"`
+		Token Token           `json:"token"`
+	}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	value, err := valueFromJSON(v.Value)
+	if err != nil {
+		return err
+	}
+	*l = Literal{literal: value, token: v.Token, origin: v.origin()}
+	return nil
+}
+
+func (va Variable) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		nodeHeader
+		Name Token `json:"name"`
+	}{header("Variable", va.origin), va.name})
+}
+
+func (va *Variable) UnmarshalJSON(data []byte) error {
+	var v struct {
+		nodeHeader
+		Name Token `json:"name"`
+	}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	*va = Variable{name: v.Name, origin: v.origin()}
+	return nil
+}
+
+func (c Call) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		nodeHeader
+		Callee    Expr   `json:"callee"`
+		Paren     Token  `json:"paren"`
+		Arguments []Expr `json:"arguments"`
+	}{header("Call", c.origin), c.callee, c.paren, c.arguments})
+}
+
+func (c *Call) UnmarshalJSON(data []byte) error {
+	var v struct {
+		nodeHeader
+		Callee    exprJSON   `json:"callee"`
+		Paren     Token      `json:"paren"`
+		Arguments []exprJSON `json:"arguments"`
+	}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	*c = Call{callee: v.Callee.Expr, paren: v.Paren, arguments: exprs(v.Arguments), origin: v.origin()}
+	return nil
+}
+
+func (a Assign) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		nodeHeader
+		Name  Token `json:"name"`
+		Value Expr  `json:"value"`
+	}{header("Assign", a.origin), a.name, a.value})
+}
+
+func (a *Assign) UnmarshalJSON(data []byte) error {
+	var v struct {
+		nodeHeader
+		Name  Token    `json:"name"`
+		Value exprJSON `json:"value"`
+	}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	*a = Assign{name: v.Name, value: v.Value.Expr, origin: v.origin()}
+	return nil
+}
+
+func (l ListLiteral) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		nodeHeader
+		Bracket  Token  `json:"bracket"`
+		Elements []Expr `json:"elements"`
+		Closing  Token  `json:"closing"`
+	}{header("ListLiteral", l.origin), l.bracket, l.elements, l.closing})
+}
+
+func (l *ListLiteral) UnmarshalJSON(data []byte) error {
+	var v struct {
+		nodeHeader
+		Bracket  Token      `json:"bracket"`
+		Elements []exprJSON `json:"elements"`
+		Closing  Token      `json:"closing"`
+	}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	*l = ListLiteral{bracket: v.Bracket, elements: exprs(v.Elements), closing: v.Closing, origin: v.origin()}
+	return nil
+}
+
+func (m MapLiteral) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		nodeHeader
+		Brace   Token  `json:"brace"`
+		Keys    []Expr `json:"keys"`
+		Values  []Expr `json:"values"`
+		Closing Token  `json:"closing"`
+	}{header("MapLiteral", m.origin), m.brace, m.keys, m.values, m.closing})
+}
+
+func (m *MapLiteral) UnmarshalJSON(data []byte) error {
+	var v struct {
+		nodeHeader
+		Brace   Token      `json:"brace"`
+		Keys    []exprJSON `json:"keys"`
+		Values  []exprJSON `json:"values"`
+		Closing Token      `json:"closing"`
+	}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	if len(v.Keys) != len(v.Values) {
+		return fmt.Errorf("map literal with %d keys and %d values", len(v.Keys), len(v.Values))
+	}
+	*m = MapLiteral{brace: v.Brace, keys: exprs(v.Keys), values: exprs(v.Values), closing: v.Closing, origin: v.origin()}
+	return nil
+}
+
+func (i Index) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		nodeHeader
+		Object  Expr  `json:"object"`
+		Bracket Token `json:"bracket"`
+		Index   Expr  `json:"index"`
+	}{header("Index", i.origin), i.object, i.bracket, i.index})
+}
+
+func (i *Index) UnmarshalJSON(data []byte) error {
+	var v struct {
+		nodeHeader
+		Object  exprJSON `json:"object"`
+		Bracket Token    `json:"bracket"`
+		Index   exprJSON `json:"index"`
+	}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	*i = Index{object: v.Object.Expr, bracket: v.Bracket, index: v.Index.Expr, origin: v.origin()}
+	return nil
+}
+
+func (s SetIndex) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		nodeHeader
+		Object  Expr  `json:"object"`
+		Bracket Token `json:"bracket"`
+		Index   Expr  `json:"index"`
+		Value   Expr  `json:"value"`
+	}{header("SetIndex", s.origin), s.object, s.bracket, s.index, s.value})
+}
+
+func (s *SetIndex) UnmarshalJSON(data []byte) error {
+	var v struct {
+		nodeHeader
+		Object  exprJSON `json:"object"`
+		Bracket Token    `json:"bracket"`
+		Index   exprJSON `json:"index"`
+		Value   exprJSON `json:"value"`
+	}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	*s = SetIndex{object: v.Object.Expr, bracket: v.Bracket, index: v.Index.Expr, value: v.Value.Expr, origin: v.origin()}
+	return nil
+}
+
+func (g Get) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		nodeHeader
+		Object Expr  `json:"object"`
+		Name   Token `json:"name"`
+	}{header("Get", g.origin), g.object, g.name})
+}
+
+func (g *Get) UnmarshalJSON(data []byte) error {
+	var v struct {
+		nodeHeader
+		Object exprJSON `json:"object"`
+		Name   Token    `json:"name"`
+	}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	*g = Get{object: v.Object.Expr, name: v.Name, origin: v.origin()}
+	return nil
+}
+
+func (s ExpressionStmt) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		nodeHeader
+		Expression Expr `json:"expression"`
+	}{header("ExpressionStmt", s.origin), s.expr})
+}
+
+func (s *ExpressionStmt) UnmarshalJSON(data []byte) error {
+	var v struct {
+		nodeHeader
+		Expression exprJSON `json:"expression"`
+	}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	*s = ExpressionStmt{expr: v.Expression.Expr, origin: v.origin()}
+	return nil
+}
+
+func (s PrintStmt) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		nodeHeader
+		Keyword    Token `json:"keyword"`
+		Expression Expr  `json:"expression"`
+	}{header("PrintStmt", s.origin), s.keyword, s.expr})
+}
+
+func (s *PrintStmt) UnmarshalJSON(data []byte) error {
+	var v struct {
+		nodeHeader
+		Keyword    Token    `json:"keyword"`
+		Expression exprJSON `json:"expression"`
+	}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	*s = PrintStmt{keyword: v.Keyword, expr: v.Expression.Expr, origin: v.origin()}
+	return nil
+}
+
+func (s VarStmt) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		nodeHeader
+		Keyword     Token `json:"keyword"`
+		Name        Token `json:"name"`
+		Initializer Expr  `json:"initializer"`
+	}{header("VarStmt", s.origin), s.keyword, s.name, s.initializer})
+}
+
+func (s *VarStmt) UnmarshalJSON(data []byte) error {
+	var v struct {
+		nodeHeader
+		Keyword     Token    `json:"keyword"`
+		Name        Token    `json:"name"`
+		Initializer exprJSON `json:"initializer"`
+	}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	*s = VarStmt{keyword: v.Keyword, name: v.Name, initializer: v.Initializer.Expr, origin: v.origin()}
+	return nil
+}
+
+func (s BlockStmt) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		nodeHeader
+		Brace      Token  `json:"brace"`
+		Statements []Stmt `json:"statements"`
+		Closing    Token  `json:"closing"`
+	}{header("BlockStmt", s.origin), s.brace, s.statements, s.closing})
+}
+
+func (s *BlockStmt) UnmarshalJSON(data []byte) error {
+	var v struct {
+		nodeHeader
+		Brace      Token      `json:"brace"`
+		Statements []stmtJSON `json:"statements"`
+		Closing    Token      `json:"closing"`
+	}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	*s = BlockStmt{brace: v.Brace, statements: stmts(v.Statements), closing: v.Closing, origin: v.origin()}
+	return nil
+}
+
+func (s ForInStmt) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		nodeHeader
+		Keyword  Token `json:"keyword"`
+		Name     Token `json:"name"`
+		Iterable Expr  `json:"iterable"`
+		Body     Stmt  `json:"body"`
+	}{header("ForInStmt", s.origin), s.keyword, s.name, s.iterable, s.body})
+}
+
+func (s *ForInStmt) UnmarshalJSON(data []byte) error {
+	var v struct {
+		nodeHeader
+		Keyword  Token    `json:"keyword"`
+		Name     Token    `json:"name"`
+		Iterable exprJSON `json:"iterable"`
+		Body     stmtJSON `json:"body"`
+	}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	*s = ForInStmt{keyword: v.Keyword, name: v.Name, iterable: v.Iterable.Expr, body: v.Body.Stmt, origin: v.origin()}
+	return nil
+}
+
+func (s IfStmt) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		nodeHeader
+		Keyword   Token `json:"keyword"`
+		Condition Expr  `json:"condition"`
+		Then      Stmt  `json:"then"`
+		Else      Stmt  `json:"else"`
+	}{header("IfStmt", s.origin), s.keyword, s.condition, s.thenBranch, s.elseBranch})
+}
+
+func (s *IfStmt) UnmarshalJSON(data []byte) error {
+	var v struct {
+		nodeHeader
+		Keyword   Token    `json:"keyword"`
+		Condition exprJSON `json:"condition"`
+		Then      stmtJSON `json:"then"`
+		Else      stmtJSON `json:"else"`
+	}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	*s = IfStmt{keyword: v.Keyword, condition: v.Condition.Expr, thenBranch: v.Then.Stmt, elseBranch: v.Else.Stmt, origin: v.origin()}
+	return nil
+}
+
+func (s ThrowStmt) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		nodeHeader
+		Keyword Token `json:"keyword"`
+		Value   Expr  `json:"value"`
+	}{header("ThrowStmt", s.origin), s.keyword, s.value})
+}
+
+func (s *ThrowStmt) UnmarshalJSON(data []byte) error {
+	var v struct {
+		nodeHeader
+		Keyword Token    `json:"keyword"`
+		Value   exprJSON `json:"value"`
+	}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	*s = ThrowStmt{keyword: v.Keyword, value: v.Value.Expr, origin: v.origin()}
+	return nil
+}
+
+func (s TryStmt) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		nodeHeader
+		Keyword     Token  `json:"keyword"`
+		Body        []Stmt `json:"body"`
+		CatchName   Token  `json:"catchName"`
+		CatchBody   []Stmt `json:"catchBody"`
+		FinallyBody []Stmt `json:"finallyBody"`
+		Closing     Token  `json:"closing"`
+	}{header("TryStmt", s.origin), s.keyword, s.body, s.catchName, s.catchBody, s.finallyBody, s.closing})
+}
+
+func (s *TryStmt) UnmarshalJSON(data []byte) error {
+	var v struct {
+		nodeHeader
+		Keyword     Token      `json:"keyword"`
+		Body        []stmtJSON `json:"body"`
+		CatchName   Token      `json:"catchName"`
+		CatchBody   []stmtJSON `json:"catchBody"`
+		FinallyBody []stmtJSON `json:"finallyBody"`
+		Closing     Token      `json:"closing"`
+	}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	*s = TryStmt{
+		keyword:     v.Keyword,
+		body:        stmts(v.Body),
+		catchName:   v.CatchName,
+		catchBody:   stmts(v.CatchBody),
+		finallyBody: stmts(v.FinallyBody),
+		closing:     v.Closing,
+		origin:      v.origin(),
+	}
+	return nil
+}
+
+// MarshalJSON writes the token with its type by name, as in
+// {"type": "Plus", "lexeme": "+", "literal": null, "line": 1}.
+func (t Token) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Type    string `json:"type"`
+		Lexeme  string `json:"lexeme"`
+		Literal any    `json:"literal"`
+		Line    int    `json:"line"`
+		Column  int    `json:"column"`
+		Offset  int    `json:"offset"`
+	}{t.TokenType.String(), t.Lexeme, jsonValue(t.Object), t.Line, t.Column, t.Offset})
+}
+
+func (t *Token) UnmarshalJSON(data []byte) error {
+	var v struct {
+		Type    string          `json:"type"`
+		Lexeme  string          `json:"lexeme"`
+		Literal json.RawMessage `json:"literal"`
+		Line    int             `json:"line"`
+		Column  int             `json:"column"`
+		Offset  int             `json:"offset"`
+	}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	tokenType, ok := tokenTypeNamed(v.Type)
+	if !ok {
+		return fmt.Errorf("unknown token type %q", v.Type)
+	}
+	object, err := valueFromJSON(v.Literal)
+	if err != nil {
+		return err
+	}
+	*t = NewToken(tokenType, v.Lexeme, object, v.Line)
+	t.Column, t.Offset = v.Column, v.Offset
+	return nil
+}
+
+// tokenTypeNamed finds the TokenType whose String method returns name.
+func tokenTypeNamed(name string) (TokenType, bool) {
+	for idx := 0; idx < len(_TokenType_index)-1; idx++ {
+		if t := TokenType(idx); t.String() == name {
+			return t, true
+		}
+	}
+	return 0, false
+}
+
+// jsonValue prepares a literal value for encoding. JSON has no way to write
+// infinities or NaN, which constant folding can produce, so those numbers are
+// written as {"number": "+Inf"} and the like.
+func jsonValue(v any) any {
+	if n, ok := v.(float64); ok && (math.IsInf(n, 0) || math.IsNaN(n)) {
+		return map[string]string{"number": strconv.FormatFloat(n, 'g', -1, 64)}
+	}
+	return v
+}
+
+// valueFromJSON decodes a literal value written by jsonValue.
+func valueFromJSON(data json.RawMessage) (any, error) {
+	if len(data) == 0 {
+		return nil, nil
+	}
+	var special struct {
+		Number *string `json:"number"`
+	}
+	if data[0] == '{' {
+		if err := json.Unmarshal(data, &special); err != nil {
+			return nil, err
+		}
+		if special.Number == nil {
+			return nil, fmt.Errorf("invalid literal value %s", data)
+		}
+		return strconv.ParseFloat(*special.Number, 64)
+	}
+
+	var value any
+	if err := json.Unmarshal(data, &value); err != nil {
+		return nil, err
+	}
+	switch value.(type) {
+	case nil, bool, float64, string:
+		return value, nil
+	}
+	return nil, fmt.Errorf("invalid literal value %s", data)
+}
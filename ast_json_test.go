@@ -0,0 +1,124 @@
+// ABOUTME: Tests for encoding syntax trees as JSON and decoding them again
+// ABOUTME: Round-trips parsed and optimized programs and checks the JSON shape
+package lox
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestASTJSON_RoundTrip(t *testing.T) {
+	sources := []string{
+		"1 + 2 * -3",
+		`var greeting = "hi" + nil; print greeting;`,
+		"var x; x = !true; (x);",
+		`var xs = [1, [2], f(3, g())]; xs[0] = xs[1][0]; print xs.len();`,
+		`var m = {"a": 1, 2: false}; {} { var y = m["a"]; }`,
+		"for x in xs { print x; } if (x) print 1; if (y) {} else print 2;",
+		`try { throw "boom"; } catch (e) { print e.message; } finally { print "done"; }`,
+		"try {} finally {}",
+		"print 1 >= 2 == 3 != 4 < 5 <= 6 > 7 - 8 / 9;",
+	}
+
+	for _, source := range sources {
+		t.Run(source, func(t *testing.T) {
+			asrt := assert.New(t)
+			statements := parseProgram(t, source)
+
+			data, err := json.Marshal(statements)
+			asrt.NoError(err)
+			decoded, err := UnmarshalProgram(data)
+			asrt.NoError(err)
+
+			asrt.Equal(statements, decoded)
+			asrt.Len(decoded, len(statements))
+			for idx := range statements {
+				asrt.Equal(printStmt(statements[idx]), printStmt(decoded[idx]))
+			}
+		})
+	}
+}
+
+func TestASTJSON_Shape(t *testing.T) {
+	data, err := json.Marshal(parseSource(t, "-x"))
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{
+		"type": "Unary",
+		"operator": {"type": "Minus", "lexeme": "-", "literal": null, "line": 1, "column": 1, "offset": 0},
+		"right": {"type": "Variable", "name": {"type": "Identifier", "lexeme": "x", "literal": null, "line": 1, "column": 2, "offset": 1}}
+	}`, string(data))
+}
+
+func TestASTJSON_Expr(t *testing.T) {
+	asrt := assert.New(t)
+	expr := parseSource(t, `f("a", 1.5)[0].len`)
+
+	data, err := json.Marshal(expr)
+	asrt.NoError(err)
+	decoded, err := UnmarshalExpr(data)
+	asrt.NoError(err)
+	asrt.Equal(expr, decoded)
+}
+
+func TestASTJSON_Stmt(t *testing.T) {
+	asrt := assert.New(t)
+	stmt := parseProgram(t, "var x;")[0]
+
+	data, err := json.Marshal(stmt)
+	asrt.NoError(err)
+	decoded, err := UnmarshalStmt(data)
+	asrt.NoError(err)
+	asrt.Equal(stmt, decoded)
+	asrt.Nil(decoded.(VarStmt).Initializer())
+}
+
+func TestASTJSON_OptimizedProgram(t *testing.T) {
+	asrt := assert.New(t)
+	statements := Optimize(parseProgram(t, "print 1 / 0;\nprint -(0 / 0);\nif (true)\n{ print (2 + 3) * x; }"))
+
+	data, err := json.Marshal(statements)
+	asrt.NoError(err)
+	decoded, err := UnmarshalProgram(data)
+	asrt.NoError(err)
+
+	asrt.Equal(printStmt(statements[0]), printStmt(decoded[0]))
+	asrt.True(math.IsNaN(decoded[1].(PrintStmt).Expression().(Literal).Value().(float64)))
+	asrt.Equal(statements[2], decoded[2])
+	asrt.Equal(Origin{Pos: Position{Line: 3, Column: 1, Offset: 29}, End: Position{Line: 4, Column: 23, Offset: 61}, Line: 4}, OriginOf(decoded[2]))
+}
+
+func TestASTJSON_Errors(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     string
+		errorMsg string
+	}{
+		{name: "unknown node type", data: `[{"type": "WhileStmt"}]`, errorMsg: `unknown node type "WhileStmt"`},
+		{name: "expression as statement", data: `[{"type": "Variable"}]`, errorMsg: "expected a statement, got lox.Variable"},
+		{
+			name:     "unknown token type",
+			data:     `[{"type": "PrintStmt", "keyword": {"type": "Shout"}}]`,
+			errorMsg: `unknown token type "Shout"`,
+		},
+		{
+			name:     "invalid literal",
+			data:     `[{"type": "ExpressionStmt", "expression": {"type": "Literal", "value": [1]}}]`,
+			errorMsg: "invalid literal value [1]",
+		},
+		{
+			name:     "mismatched map",
+			data:     `[{"type": "ExpressionStmt", "expression": {"type": "MapLiteral", "keys": [], "values": [null]}}]`,
+			errorMsg: "map literal with 0 keys and 1 values",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := UnmarshalProgram([]byte(tt.data))
+			assert.EqualError(t, err, tt.errorMsg)
+		})
+	}
+}
@@ -1,44 +1,186 @@
 package lox
 
-import "fmt"
+import (
+	"fmt"
+	"strings"
+)
 
-// astPrinter is a visitor that converts an expression tree to a string representation
-// This makes it easy to verify the structure of parsed expressions
+// AstPrinter converts syntax trees to a parenthesized string representation,
+// which makes it easy to verify the structure of parsed code. It is also a
+// Visitor, for callers of Expr.Accept written before ExprVisitor: after
+// expr.Accept(printer), Result returns what Print would have.
 type AstPrinter struct {
 	result string
 }
 
+// Print returns the string representation of expr.
+func (ap *AstPrinter) Print(expr Expr) string {
+	return astPrinter{}.Print(expr)
+}
+
+// PrintStmt returns the string representation of stmt.
+func (ap *AstPrinter) PrintStmt(stmt Stmt) string {
+	return astPrinter{}.PrintStmt(stmt)
+}
+
+// Result returns the string representation of the expression last visited.
 func (ap *AstPrinter) Result() string {
 	return ap.result
 }
 
-func (ap *AstPrinter) VisitBinary(b Binary) {
-	left := printExpr(b.left)
-	right := printExpr(b.right)
-	ap.result = fmt.Sprintf("(%s %s %s)", b.operator.Lexeme, left, right)
+func (ap *AstPrinter) VisitBinary(b Binary)     { ap.result = ap.Print(b) }
+func (ap *AstPrinter) VisitUnary(u Unary)       { ap.result = ap.Print(u) }
+func (ap *AstPrinter) VisitGroup(g Group)       { ap.result = ap.Print(g) }
+func (ap *AstPrinter) VisitLiteral(l Literal)   { ap.result = ap.Print(l) }
+func (ap *AstPrinter) VisitVariable(v Variable) { ap.result = ap.Print(v) }
+func (ap *AstPrinter) VisitCall(c Call)         { ap.result = ap.Print(c) }
+func (ap *AstPrinter) VisitAssign(a Assign)     { ap.result = ap.Print(a) }
+func (ap *AstPrinter) VisitList(l ListLiteral)  { ap.result = ap.Print(l) }
+func (ap *AstPrinter) VisitMap(m MapLiteral)    { ap.result = ap.Print(m) }
+func (ap *AstPrinter) VisitIndex(i Index)       { ap.result = ap.Print(i) }
+func (ap *AstPrinter) VisitSetIndex(s SetIndex) { ap.result = ap.Print(s) }
+func (ap *AstPrinter) VisitGet(g Get)           { ap.result = ap.Print(g) }
+
+// astPrinter is the ExprVisitor and StmtVisitor behind AstPrinter.
+type astPrinter struct{}
+
+func (ap astPrinter) Print(expr Expr) string {
+	result, err := Accept[string](expr, ap)
+	if err != nil {
+		return fmt.Sprintf("(unknown %T)", expr)
+	}
+	return result
+}
+
+func (ap astPrinter) PrintStmt(stmt Stmt) string {
+	result, err := AcceptStmt[string](stmt, ap)
+	if err != nil {
+		return fmt.Sprintf("(unknown %T)", stmt)
+	}
+	return result
 }
 
-func (ap *AstPrinter) VisitUnary(u Unary) {
-	right := printExpr(u.right)
-	ap.result = fmt.Sprintf("(%s %s)", u.operator.Lexeme, right)
+func (ap astPrinter) VisitBinary(b Binary) (string, error) {
+	left := ap.Print(b.left)
+	right := ap.Print(b.right)
+	return fmt.Sprintf("(%s %s %s)", b.operator.Lexeme, left, right), nil
 }
 
-func (ap *AstPrinter) VisitGroup(g Group) {
-	inner := printExpr(g.expr)
-	ap.result = fmt.Sprintf("(group %s)", inner)
+func (ap astPrinter) VisitUnary(u Unary) (string, error) {
+	right := ap.Print(u.right)
+	return fmt.Sprintf("(%s %s)", u.operator.Lexeme, right), nil
 }
 
-func (ap *AstPrinter) VisitLiteral(l Literal) {
+func (ap astPrinter) VisitGroup(g Group) (string, error) {
+	inner := ap.Print(g.expr)
+	return fmt.Sprintf("(group %s)", inner), nil
+}
+
+func (ap astPrinter) VisitLiteral(l Literal) (string, error) {
 	if l.literal == nil {
-		ap.result = "nil"
-	} else {
-		ap.result = fmt.Sprintf("%v", l.literal)
+		return "nil", nil
+	}
+	return fmt.Sprintf("%v", l.literal), nil
+}
+
+func (ap astPrinter) VisitVariable(v Variable) (string, error) {
+	return v.name.Lexeme, nil
+}
+
+func (ap astPrinter) VisitCall(c Call) (string, error) {
+	parts := []string{"call", ap.Print(c.callee)}
+	for _, arg := range c.arguments {
+		parts = append(parts, ap.Print(arg))
+	}
+	return fmt.Sprintf("(%s)", strings.Join(parts, " ")), nil
+}
+
+func (ap astPrinter) VisitAssign(a Assign) (string, error) {
+	return fmt.Sprintf("(= %s %s)", a.name.Lexeme, ap.Print(a.value)), nil
+}
+
+func (ap astPrinter) VisitList(l ListLiteral) (string, error) {
+	parts := []string{"list"}
+	for _, element := range l.elements {
+		parts = append(parts, ap.Print(element))
+	}
+	return fmt.Sprintf("(%s)", strings.Join(parts, " ")), nil
+}
+
+func (ap astPrinter) VisitMap(m MapLiteral) (string, error) {
+	parts := []string{"map"}
+	for idx, key := range m.keys {
+		parts = append(parts, ap.Print(key), ap.Print(m.values[idx]))
+	}
+	return fmt.Sprintf("(%s)", strings.Join(parts, " ")), nil
+}
+
+func (ap astPrinter) VisitIndex(i Index) (string, error) {
+	return fmt.Sprintf("(index %s %s)", ap.Print(i.object), ap.Print(i.index)), nil
+}
+
+func (ap astPrinter) VisitSetIndex(s SetIndex) (string, error) {
+	return fmt.Sprintf("(= (index %s %s) %s)", ap.Print(s.object), ap.Print(s.index), ap.Print(s.value)), nil
+}
+
+func (ap astPrinter) VisitGet(g Get) (string, error) {
+	return fmt.Sprintf("(. %s %s)", ap.Print(g.object), g.name.Lexeme), nil
+}
+
+func (ap astPrinter) VisitExpressionStmt(s ExpressionStmt) (string, error) {
+	return fmt.Sprintf("(; %s)", ap.Print(s.expr)), nil
+}
+
+func (ap astPrinter) VisitPrintStmt(s PrintStmt) (string, error) {
+	return fmt.Sprintf("(print %s)", ap.Print(s.expr)), nil
+}
+
+func (ap astPrinter) VisitVarStmt(s VarStmt) (string, error) {
+	if s.initializer == nil {
+		return fmt.Sprintf("(var %s)", s.name.Lexeme), nil
+	}
+	return fmt.Sprintf("(var %s %s)", s.name.Lexeme, ap.Print(s.initializer)), nil
+}
+
+func (ap astPrinter) VisitBlockStmt(s BlockStmt) (string, error) {
+	parts := []string{"block"}
+	for _, stmt := range s.statements {
+		parts = append(parts, ap.PrintStmt(stmt))
 	}
+	return fmt.Sprintf("(%s)", strings.Join(parts, " ")), nil
+}
+
+func (ap astPrinter) VisitForInStmt(s ForInStmt) (string, error) {
+	return fmt.Sprintf("(for %s %s %s)", s.name.Lexeme, ap.Print(s.iterable), ap.PrintStmt(s.body)), nil
+}
+
+func (ap astPrinter) VisitIfStmt(s IfStmt) (string, error) {
+	if s.elseBranch == nil {
+		return fmt.Sprintf("(if %s %s)", ap.Print(s.condition), ap.PrintStmt(s.thenBranch)), nil
+	}
+	return fmt.Sprintf("(if %s %s %s)", ap.Print(s.condition), ap.PrintStmt(s.thenBranch), ap.PrintStmt(s.elseBranch)), nil
+}
+
+func (ap astPrinter) VisitThrowStmt(s ThrowStmt) (string, error) {
+	return fmt.Sprintf("(throw %s)", ap.Print(s.value)), nil
+}
+
+func (ap astPrinter) VisitTryStmt(s TryStmt) (string, error) {
+	parts := []string{"try", ap.PrintStmt(BlockStmt{statements: s.body})}
+	if s.catchBody != nil {
+		parts = append(parts, fmt.Sprintf("(catch %s %s)", s.catchName.Lexeme, ap.PrintStmt(BlockStmt{statements: s.catchBody})))
+	}
+	if s.finallyBody != nil {
+		parts = append(parts, fmt.Sprintf("(finally %s)", ap.PrintStmt(BlockStmt{statements: s.finallyBody})))
+	}
+	return fmt.Sprintf("(%s)", strings.Join(parts, " ")), nil
 }
 
 // printExpr is a helper function to convert any expression to its string representation
 func printExpr(expr Expr) string {
-	printer := &AstPrinter{}
-	expr.Accept(printer)
-	return printer.result
+	return (&AstPrinter{}).Print(expr)
+}
+
+func printStmt(stmt Stmt) string {
+	return (&AstPrinter{}).PrintStmt(stmt)
 }
@@ -0,0 +1,125 @@
+package lox
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Benchmark is one of the standard Lox programs used to measure how fast
+// glox runs Lox. Each program ends with an expression statement, so running
+// it produces Result.
+type Benchmark struct {
+	Name   string
+	Source string
+	Result Value
+}
+
+// Benchmarks returns the standard benchmark programs. Lox has no functions
+// or classes yet, so the programs loop over lists where the usual versions
+// would recurse, call list methods as their method calls and use map keys as
+// their fields.
+func Benchmarks() []Benchmark {
+	return []Benchmark{
+		{Name: "fib", Source: fibSource(100), Result: 832040.0},
+		{Name: "binary_trees", Source: binaryTreesSource(4, 10), Result: 4.0 * 2047},
+		{Name: "string_concatenation", Source: stringConcatenationSource(2000), Result: 2000.0 * 4},
+		{Name: "method_calls", Source: methodCallsSource(2000), Result: 2000.0 * 2001 / 2},
+		{Name: "zoo", Source: zooSource(5000), Result: 5000.0 * 21},
+	}
+}
+
+// fibSource computes the 30th Fibonacci number rounds times.
+func fibSource(rounds int) string {
+	return fmt.Sprintf(`var rounds = [%s];
+var steps = [%s];
+var a;
+var b;
+for round in rounds {
+  a = 0;
+  b = 1;
+  for step in steps {
+    var next = a + b;
+    a = b;
+    b = next;
+  }
+}
+a;`, zeros(rounds), zeros(30))
+}
+
+// binaryTreesSource builds trees complete binary trees of the given depth,
+// with each node a list of its two children, and counts their nodes. Trees
+// are built a level at a time from the leaves, and walked breadth first by
+// looping over a queue that grows as it goes.
+func binaryTreesSource(trees, depth int) string {
+	return fmt.Sprintf(`var count = 0;
+for tree in [%s] {
+  var level = [];
+  for leaf in [%s] {
+    level.push([nil, nil]);
+  }
+  for d in [%s] {
+    var next = [];
+    var left = nil;
+    for node in level {
+      if (left == nil) left = node;
+      else {
+        next.push([left, node]);
+        left = nil;
+      }
+    }
+    level = next;
+  }
+
+  var queue = [level[0]];
+  for node in queue {
+    count = count + 1;
+    if (node[0] != nil) {
+      queue.push(node[0]);
+      queue.push(node[1]);
+    }
+  }
+}
+count;`, zeros(trees), zeros(1<<depth), zeros(depth))
+}
+
+// stringConcatenationSource builds a string from n short pieces, and
+// returns its length.
+func stringConcatenationSource(n int) string {
+	return fmt.Sprintf(`var s = "";
+for n in [%s] {
+  s = s + "ab" + str(n) + "c";
+}
+len(s);`, zeros(n))
+}
+
+// methodCallsSource pushes n values onto a list and pops them off again,
+// checking the length as it goes.
+func methodCallsSource(n int) string {
+	return fmt.Sprintf(`var xs = [];
+for n in [%[1]s] {
+  xs.push(n);
+}
+var total = 0;
+for n in [%[1]s] {
+  total = total + xs.len();
+  xs.pop();
+}
+total;`, zeros(n))
+}
+
+// zooSource reads and writes the fields of a map of animals n times.
+func zooSource(n int) string {
+	return fmt.Sprintf(`var zoo = {"aardvark": 1, "baboon": 2, "cat": 3, "donkey": 4, "elephant": 5, "fox": 6};
+var sum = 0;
+for n in [%s] {
+  sum = sum + zoo["aardvark"] + zoo["baboon"] + zoo["cat"] +
+    zoo["donkey"] + zoo["elephant"] + zoo["fox"];
+  zoo["cat"] = zoo["cat"];
+}
+sum;`, zeros(n))
+}
+
+// zeros is the elements of a list of n zeros, to loop over n times.
+func zeros(n int) string {
+	return strings.TrimSuffix(strings.Repeat("0, ", n), ", ")
+}
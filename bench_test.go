@@ -0,0 +1,83 @@
+// ABOUTME: Benchmarks for the scanner, the parser and the standard Lox benchmark programs
+// ABOUTME: Also checks that every benchmark program runs and produces its expected result
+package lox
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBenchmarks(t *testing.T) {
+	for _, bench := range Benchmarks() {
+		t.Run(bench.Name, func(t *testing.T) {
+			result, err := NewInterpreter().Execute(parseProgram(t, bench.Source))
+			assert.NoError(t, err)
+			assert.Equal(t, bench.Result, result)
+		})
+	}
+}
+
+// largeSource is every benchmark program, repeated until it is over a
+// megabyte long.
+func largeSource() string {
+	var source strings.Builder
+	for source.Len() < 1<<20 {
+		for _, bench := range Benchmarks() {
+			source.WriteString(bench.Source)
+			source.WriteString("\n// comments are scanned too\n")
+		}
+	}
+	return source.String()
+}
+
+func BenchmarkScanner(b *testing.B) {
+	source := largeSource()
+	b.SetBytes(int64(len(source)))
+	for b.Loop() {
+		_, err := NewScanner(source).ScanTokens()
+		assert.NoError(b, err)
+	}
+}
+
+func BenchmarkParser(b *testing.B) {
+	sources := []struct {
+		name   string
+		source string
+	}{
+		{name: "nested groups", source: strings.Repeat("(", 1000) + "1" + strings.Repeat(")", 1000)},
+		{name: "nested unary", source: strings.Repeat("-!", 1000) + "x"},
+		{name: "nested lists", source: strings.Repeat("[1, ", 1000) + "1" + strings.Repeat("]", 1000)},
+		{name: "long chain", source: "1" + strings.Repeat(" + 2 * 3 - x", 1000)},
+		{name: "large program", source: largeSource()},
+	}
+
+	for _, tt := range sources {
+		tokens, err := NewScanner(tt.source).ScanTokens()
+		assert.NoError(b, err)
+		b.Run(tt.name, func(b *testing.B) {
+			for b.Loop() {
+				_, err := NewParser(tokens).ParseProgram()
+				assert.NoError(b, err)
+			}
+		})
+	}
+}
+
+func BenchmarkPrograms(b *testing.B) {
+	for _, bench := range Benchmarks() {
+		tokens, err := NewScanner(bench.Source).ScanTokens()
+		assert.NoError(b, err)
+		statements, err := NewParser(tokens).ParseProgram()
+		assert.NoError(b, err)
+
+		b.Run(bench.Name, func(b *testing.B) {
+			for b.Loop() {
+				result, err := NewInterpreter().Execute(statements)
+				assert.NoError(b, err)
+				assert.Equal(b, bench.Result, result)
+			}
+		})
+	}
+}
@@ -0,0 +1,42 @@
+package lox
+
+import "fmt"
+
+// Callable is a value that can be invoked from Lox with call syntax.
+type Callable interface {
+	// Arity is the number of arguments the callable expects, or -1 if it
+	// accepts any number.
+	Arity() int
+	Call(i *Interpreter, args []Value) (Value, error)
+}
+
+// NativeFunction is a Callable implemented in Go.
+type NativeFunction struct {
+	name  string
+	arity int
+	fn    func(i *Interpreter, args []Value) (Value, error)
+}
+
+func NewNativeFunction(name string, arity int, fn func(args []Value) (Value, error)) *NativeFunction {
+	return native(name, arity, func(_ *Interpreter, args []Value) (Value, error) {
+		return fn(args)
+	})
+}
+
+// native builds a NativeFunction that can use the calling interpreter, e.g. to
+// account for the memory it allocates.
+func native(name string, arity int, fn func(i *Interpreter, args []Value) (Value, error)) *NativeFunction {
+	return &NativeFunction{name: name, arity: arity, fn: fn}
+}
+
+func (n *NativeFunction) Arity() int {
+	return n.arity
+}
+
+func (n *NativeFunction) Call(i *Interpreter, args []Value) (Value, error) {
+	return n.fn(i, args)
+}
+
+func (n *NativeFunction) String() string {
+	return fmt.Sprintf("<native fn %s>", n.name)
+}
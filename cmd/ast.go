@@ -0,0 +1,49 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	lox "github.com/mikowitz/glox"
+)
+
+// runAST prints the syntax tree of the script in filename, one statement per
+// line, or as a JSON array of statements if asJSON is set.
+func runAST(filename string, asJSON bool, stdout, stderr io.Writer) int {
+	bytes, err := os.ReadFile(filename)
+	if err != nil {
+		return ExitInputError
+	}
+
+	scanner := lox.NewScanner(string(bytes))
+	tokens, err := scanner.ScanTokens()
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return ExitSyntaxError
+	}
+
+	parser := lox.NewParser(tokens)
+	statements, err := parser.ParseProgram()
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return ExitSyntaxError
+	}
+
+	if asJSON {
+		data, err := json.MarshalIndent(statements, "", "  ")
+		if err != nil {
+			fmt.Fprintln(stderr, err)
+			return ExitIOError
+		}
+		fmt.Fprintln(stdout, string(data))
+		return ExitSuccess
+	}
+
+	printer := &lox.AstPrinter{}
+	for _, stmt := range statements {
+		fmt.Fprintln(stdout, printer.PrintStmt(stmt))
+	}
+	return ExitSuccess
+}
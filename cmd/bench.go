@@ -0,0 +1,60 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"testing"
+	"time"
+
+	lox "github.com/mikowitz/glox"
+)
+
+// runBench runs the standard benchmark programs named in names, or all of
+// them if there are none, and reports how many times a second each runs.
+// Programs are parsed once, and each run is a fresh interpreter executing
+// the parsed program, so only the interpreter is measured.
+func runBench(names []string, stdout, stderr io.Writer) int {
+	benchmarks := lox.Benchmarks()
+	for _, name := range names {
+		if !slices.ContainsFunc(benchmarks, func(b lox.Benchmark) bool { return b.Name == name }) {
+			fmt.Fprintf(stderr, "unknown benchmark %q\n", name)
+			return ExitUsageError
+		}
+	}
+
+	for _, bench := range benchmarks {
+		if len(names) > 0 && !slices.Contains(names, bench.Name) {
+			continue
+		}
+
+		tokens, err := lox.NewScanner(bench.Source).ScanTokens()
+		if err != nil {
+			fmt.Fprintf(stderr, "%s: %s\n", bench.Name, err)
+			return ExitSyntaxError
+		}
+		statements, err := lox.NewParser(tokens).ParseProgram()
+		if err != nil {
+			fmt.Fprintf(stderr, "%s: %s\n", bench.Name, err)
+			return ExitSyntaxError
+		}
+		result, err := lox.NewInterpreter().Execute(statements)
+		if err != nil {
+			fmt.Fprintf(stderr, "%s: %s\n", bench.Name, err)
+			return ExitRuntimeError
+		}
+		if result != bench.Result {
+			fmt.Fprintf(stderr, "%s: got %s, expected %s\n", bench.Name, lox.Stringify(result), lox.Stringify(bench.Result))
+			return ExitRuntimeError
+		}
+
+		measured := testing.Benchmark(func(b *testing.B) {
+			for b.Loop() {
+				lox.NewInterpreter().Execute(statements)
+			}
+		})
+		perOp := time.Duration(measured.NsPerOp()).Round(time.Microsecond)
+		fmt.Fprintf(stdout, "%-24s %10.1f ops/sec %12s/op\n", bench.Name, float64(time.Second)/float64(perOp), perOp)
+	}
+	return ExitSuccess
+}
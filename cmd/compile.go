@@ -0,0 +1,74 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	lox "github.com/mikowitz/glox"
+)
+
+// runCompile parses and optimizes the script in filename and writes it to
+// output as a compiled program. If output is empty, it is filename with its
+// extension replaced by .loxc.
+func runCompile(filename, output string, stderr io.Writer) int {
+	source, err := os.ReadFile(filename)
+	if err != nil {
+		return ExitInputError
+	}
+	if output == "" {
+		output = strings.TrimSuffix(filename, filepath.Ext(filename)) + ".loxc"
+	}
+
+	tokens, err := lox.NewScanner(string(source)).ScanTokens()
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return ExitSyntaxError
+	}
+	statements, err := lox.NewParser(tokens).ParseProgram()
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return ExitSyntaxError
+	}
+
+	var compiled bytes.Buffer
+	if err := lox.WriteCompiled(&compiled, lox.Optimize(statements)); err != nil {
+		fmt.Fprintln(stderr, err)
+		return ExitIOError
+	}
+	if err := os.WriteFile(output, compiled.Bytes(), 0o644); err != nil {
+		fmt.Fprintln(stderr, err)
+		return ExitIOError
+	}
+	return ExitSuccess
+}
+
+// runProgram runs the script in filename, which may be Lox source or a
+// program compiled by runCompile, with the interpreter options opts.
+func runProgram(filename string, stdout, stderr io.Writer, opts ...lox.Option) int {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return ExitInputError
+	}
+	opts = append([]lox.Option{lox.WithStdout(stdout), lox.WithStderr(stderr)}, opts...)
+	return run(filename, data, lox.NewInterpreter(opts...), stderr)
+}
+
+// runOptions returns the interpreter options for the flags given to run, and
+// false if any flag is unknown. The trace goes to stdout, interleaved with
+// the program's output.
+func runOptions(flags []string) ([]lox.Option, bool) {
+	var opts []lox.Option
+	for _, flag := range flags {
+		switch flag {
+		case "--trace":
+			opts = append(opts, lox.WithTrace(os.Stdout))
+		default:
+			return nil, false
+		}
+	}
+	return opts, true
+}
@@ -0,0 +1,192 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	lox "github.com/mikowitz/glox"
+)
+
+const debugHelp = `Commands:
+  break N, b N     set a breakpoint on line N
+  delete N, d N    remove the breakpoint on line N
+  step, s          stop at the next expression
+  next, n          stop at the next expression not nested in this one
+  finish, f        stop once the enclosing expression is done
+  continue, c      run until the next breakpoint
+  locals, l        print variables in every scope, innermost first
+  backtrace, bt    print the stack of expressions being evaluated
+  quit, q          stop debugging`
+
+type debugSession struct {
+	lines       []string
+	breakable   map[int]bool
+	input       *bufio.Scanner
+	out         io.Writer
+	debugger    *lox.Debugger
+	interpreter *lox.Interpreter
+}
+
+func runDebug(filename string) int {
+	bytes, err := os.ReadFile(filename)
+	if err != nil {
+		return ExitInputError
+	}
+	source := string(bytes)
+
+	scanner := lox.NewScanner(source)
+	tokens, err := scanner.ScanTokens()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return ExitSyntaxError
+	}
+
+	parser := lox.NewParser(tokens)
+	statements, err := parser.ParseProgram()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return ExitSyntaxError
+	}
+
+	interpreter := lox.NewInterpreter()
+	session := &debugSession{
+		lines:       strings.Split(source, "\n"),
+		breakable:   lox.BreakableLines(statements),
+		input:       bufio.NewScanner(interpreter.Stdin()),
+		out:         interpreter.Stdout(),
+		interpreter: interpreter,
+	}
+	session.debugger = lox.NewDebugger(session.stop)
+
+	interpreter.SetHook(session.debugger)
+	result, err := interpreter.Execute(statements)
+	if err != nil {
+		fmt.Fprintln(interpreter.Stderr(), err)
+		fmt.Fprint(interpreter.Stderr(), lox.StackTrace(err))
+		return ExitRuntimeError
+	}
+
+	if result != nil {
+		fmt.Fprintln(session.out, lox.Stringify(result))
+	}
+	return ExitSuccess
+}
+
+func (s *debugSession) stop(reason lox.StopReason, frames []lox.Frame) {
+	current := frames[len(frames)-1]
+	fmt.Fprintf(s.out, "stopped (%s) at line %d: %s\n", reason, current.Line, s.sourceLine(current.Line))
+	fmt.Fprintf(s.out, "  -> %s\n", printExpr(current.Expr))
+
+	for {
+		fmt.Fprint(s.out, "(glox) ")
+		if !s.input.Scan() {
+			s.debugger.Continue()
+			return
+		}
+
+		fields := strings.Fields(s.input.Text())
+		if len(fields) == 0 {
+			continue
+		}
+
+		switch fields[0] {
+		case "break", "b", "delete", "d":
+			if len(fields) != 2 {
+				fmt.Fprintf(s.out, "usage: %s LINE\n", fields[0])
+				continue
+			}
+			line, err := strconv.Atoi(fields[1])
+			if err != nil {
+				fmt.Fprintf(s.out, "invalid line %q\n", fields[1])
+				continue
+			}
+			if fields[0] == "break" || fields[0] == "b" {
+				s.debugger.SetBreakpoint(line)
+				fmt.Fprintf(s.out, "breakpoint set on line %d\n", line)
+				if !s.breakable[line] {
+					fmt.Fprintf(s.out, "warning: line %d has no expression, so the breakpoint will never be hit\n", line)
+				}
+			} else {
+				s.debugger.ClearBreakpoint(line)
+				fmt.Fprintf(s.out, "breakpoint removed from line %d\n", line)
+			}
+		case "step", "s":
+			s.debugger.Step()
+			return
+		case "next", "n":
+			s.debugger.Next()
+			return
+		case "finish", "f":
+			s.debugger.Finish()
+			return
+		case "continue", "c":
+			s.debugger.Continue()
+			return
+		case "locals", "l":
+			s.printLocals()
+		case "backtrace", "bt":
+			for depth := len(frames) - 1; depth >= 0; depth-- {
+				frame := frames[depth]
+				fmt.Fprintf(s.out, "#%d line %d: %s\n", len(frames)-1-depth, frame.Line, printExpr(frame.Expr))
+			}
+		case "quit", "q":
+			os.Exit(ExitSuccess)
+		case "help", "h":
+			fmt.Fprintln(s.out, debugHelp)
+		default:
+			fmt.Fprintf(s.out, "unknown command %q, try help\n", fields[0])
+		}
+	}
+}
+
+// printLocals prints the variables in each scope, innermost first and ending
+// with the globals. A variable shadowed by one further in is listed in both
+// scopes, with its own value in each.
+func (s *debugSession) printLocals() {
+	globals := s.interpreter.Globals()
+	var envs []*lox.Environment
+	for env := s.interpreter.Environment(); env != nil && env != globals; env = env.Enclosing() {
+		envs = append(envs, env)
+	}
+	envs = append(envs, globals)
+
+	empty := true
+	for idx, env := range envs {
+		names := env.Names()
+		if len(names) == 0 {
+			continue
+		}
+		empty = false
+		switch {
+		case env == globals:
+			fmt.Fprintln(s.out, "globals:")
+		case idx == 0:
+			fmt.Fprintln(s.out, "locals:")
+		default:
+			fmt.Fprintf(s.out, "enclosing scope %d:\n", idx)
+		}
+		for _, name := range names {
+			value, _ := env.Lookup(name)
+			fmt.Fprintf(s.out, "  %s = %s\n", name, lox.Stringify(value))
+		}
+	}
+	if empty {
+		fmt.Fprintln(s.out, "no variables in scope")
+	}
+}
+
+func (s *debugSession) sourceLine(line int) string {
+	if line < 1 || line > len(s.lines) {
+		return ""
+	}
+	return strings.TrimSpace(s.lines[line-1])
+}
+
+func printExpr(expr lox.Expr) string {
+	printer := &lox.AstPrinter{}
+	return printer.Print(expr)
+}
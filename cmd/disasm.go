@@ -0,0 +1,47 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+
+	lox "github.com/mikowitz/glox"
+)
+
+// runDisasm prints the disassembly of the script in filename. Lox source is
+// compiled first, exactly as runCompile would compile it, so both kinds of
+// file disassemble the same.
+func runDisasm(filename string, stdout, stderr io.Writer) int {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return ExitInputError
+	}
+
+	var statements []lox.Stmt
+	if lox.IsCompiled(data) {
+		statements, err = lox.ReadCompiled(bytes.NewReader(data))
+		if err != nil {
+			fmt.Fprintf(stderr, "%s: %s\n", filename, err)
+			return ExitInputError
+		}
+	} else {
+		tokens, err := lox.NewScanner(string(data)).ScanTokens()
+		if err != nil {
+			fmt.Fprintln(stderr, err)
+			return ExitSyntaxError
+		}
+		parsed, err := lox.NewParser(tokens).ParseProgram()
+		if err != nil {
+			fmt.Fprintln(stderr, err)
+			return ExitSyntaxError
+		}
+		statements = lox.Optimize(parsed)
+	}
+
+	if err := lox.Disassemble(stdout, statements); err != nil {
+		fmt.Fprintln(stderr, err)
+		return ExitIOError
+	}
+	return ExitSuccess
+}
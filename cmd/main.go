@@ -2,10 +2,14 @@ package main
 
 import (
 	"bufio"
+	"errors"
 	"fmt"
+	"io"
 	"os"
 
 	lox "github.com/mikowitz/glox"
+	"github.com/mikowitz/glox/dap"
+	"github.com/mikowitz/glox/lsp"
 )
 
 const (
@@ -18,8 +22,41 @@ const (
 )
 
 func main() {
+	if len(os.Args) == 2 && os.Args[1] == "lsp" {
+		os.Exit(runLSP())
+	}
+	if len(os.Args) == 2 && os.Args[1] == "dap" {
+		os.Exit(runDAP())
+	}
+	if len(os.Args) == 3 && os.Args[1] == "debug" {
+		os.Exit(runDebug(os.Args[2]))
+	}
+	if len(os.Args) == 3 && os.Args[1] == "ast" {
+		os.Exit(runAST(os.Args[2], false, os.Stdout, os.Stderr))
+	}
+	if len(os.Args) == 4 && os.Args[1] == "ast" && os.Args[2] == "--json" {
+		os.Exit(runAST(os.Args[3], true, os.Stdout, os.Stderr))
+	}
+	if len(os.Args) == 3 && os.Args[1] == "compile" {
+		os.Exit(runCompile(os.Args[2], "", os.Stderr))
+	}
+	if len(os.Args) == 5 && os.Args[1] == "compile" && os.Args[3] == "-o" {
+		os.Exit(runCompile(os.Args[2], os.Args[4], os.Stderr))
+	}
+	if len(os.Args) >= 3 && os.Args[1] == "run" {
+		if opts, ok := runOptions(os.Args[2 : len(os.Args)-1]); ok {
+			os.Exit(runProgram(os.Args[len(os.Args)-1], os.Stdout, os.Stderr, opts...))
+		}
+	}
+	if len(os.Args) >= 2 && os.Args[1] == "bench" {
+		os.Exit(runBench(os.Args[2:], os.Stdout, os.Stderr))
+	}
+	if len(os.Args) == 3 && os.Args[1] == "disasm" {
+		os.Exit(runDisasm(os.Args[2], os.Stdout, os.Stderr))
+	}
+
 	if len(os.Args) > 2 {
-		fmt.Fprintln(os.Stderr, "Usage: glox [script]")
+		fmt.Fprintln(os.Stderr, "Usage: glox [script | run [--trace] script | compile script [-o out.loxc] | disasm script | bench [name...] | lsp | dap | debug script | ast [--json] script]")
 		os.Exit(ExitUsageError)
 	} else if len(os.Args) == 2 {
 		exitCode := runFile(os.Args[1])
@@ -30,52 +67,59 @@ func main() {
 }
 
 func runFile(filename string) int {
-	bytes, err := os.ReadFile(filename)
-	if err != nil {
-		return ExitInputError
+	return runProgram(filename, os.Stdout, os.Stderr)
+}
+
+func runLSP() int {
+	server := lsp.NewServer(os.Stdin, os.Stdout)
+	if err := server.Run(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return ExitIOError
 	}
-	return run(string(bytes))
+	return ExitSuccess
 }
 
+func runDAP() int {
+	server := dap.NewServer(os.Stdin, os.Stdout)
+	if err := server.Run(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return ExitIOError
+	}
+	return ExitSuccess
+}
+
+// runPrompt runs each line read from stdin with one Interpreter, so that
+// variables defined on one line can be used on the next.
 func runPrompt() {
 	scanner := bufio.NewScanner(os.Stdin)
+	interpreter := lox.NewInterpreter()
 
 	for {
 		fmt.Fprint(os.Stdout, "> ")
 		if scanner.Scan() {
-			run(scanner.Text())
+			run("<stdin>", scanner.Bytes(), interpreter, os.Stderr)
 		} else {
 			os.Exit(ExitIOError)
 		}
 	}
 }
 
-func run(source string) int {
-	scanner := lox.NewScanner(source)
-	tokens, err := scanner.ScanTokens()
-	if err != nil {
-		fmt.Fprintln(os.Stderr, err)
+// run runs a script with interpreter, reporting any error it stops with to
+// stderr, and returns the exit status for it. A compiled script that can't be
+// read is reported as coming from filename.
+func run(filename string, script []byte, interpreter *lox.Interpreter, stderr io.Writer) int {
+	err := interpreter.RunScript(script)
+	switch {
+	case err == nil:
+		return ExitSuccess
+	case errors.Is(err, lox.ErrLoxSyntax):
+		fmt.Fprintln(stderr, err)
 		return ExitSyntaxError
-	}
-
-	fmt.Printf("%+#v\n", tokens)
-
-	parser := lox.NewParser(tokens)
-	expr, err := parser.Parse()
-	if err != nil {
-		fmt.Fprintln(os.Stderr, err)
-		return ExitSyntaxError
-	}
-
-	fmt.Printf("%+#v\n", expr)
-
-	interpreter := lox.NewInterpreter()
-	result, err := interpreter.Interpret(expr)
-	if err != nil {
-		fmt.Fprintln(os.Stderr, err)
+	case errors.Is(err, lox.ErrLoxRuntime):
+		fmt.Fprintln(stderr, err)
+		fmt.Fprint(stderr, lox.StackTrace(err))
 		return ExitRuntimeError
 	}
-
-	fmt.Println(result)
-	return ExitSuccess
+	fmt.Fprintf(stderr, "%s: %s\n", filename, err)
+	return ExitInputError
 }
@@ -0,0 +1,776 @@
+package lox
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"math"
+)
+
+// CompiledVersion is the version of the compiled program format that
+// WriteCompiled writes and ReadCompiled accepts. It changes whenever the
+// layout or the meaning of a node tag does.
+const CompiledVersion = 2
+
+// compiledMagic starts every compiled program.
+var compiledMagic = [4]byte{'L', 'O', 'X', 'C'}
+
+var (
+	ErrNotCompiled     = errors.New("not a compiled Lox program")
+	ErrCompiledVersion = errors.New("unsupported compiled Lox version")
+	ErrCompiledCorrupt = errors.New("corrupt compiled Lox program")
+)
+
+// A compiled program, as written to .loxc files, is laid out as:
+//
+//	magic     "LOXC"
+//	version   uint16, little-endian
+//	constants uvarint count, then per constant a kind byte and its value:
+//	          a float64 for numbers, a uvarint length and bytes for strings
+//	lines     uvarint count of runs, then per run the uvarint line and the
+//	          uvarint number of consecutive tokens on it
+//	code      the program's statements, as a list
+//	checksum  uint32 CRC-32 (IEEE) of everything before it, little-endian
+//
+// Each node in the code is a tag byte followed by its fields and its origin,
+// and a missing node is a single tagNone. Lists of nodes are a uvarint one
+// higher than their length, or 0 for a missing list, then the nodes. Tokens
+// are the uvarint number of their type from tokenTypesOnWire, the constant
+// index of their lexeme, a uvarint that is 0 for no literal or one more
+// than the constant index of it, and their uvarint column and offset; their
+// lines come from the line table, one per token in the order tokens appear.
+// Origins are a byte that is 0 for none, or 1 followed by the uvarint line,
+// column and offset of their start and then of their end, and their line.
+// Lox has no functions of its own yet, so the constant pool holds only
+// numbers and strings.
+
+const (
+	constNumber byte = iota + 1
+	constString
+)
+
+// tagNone stands in for a missing optional node, such as a variable without
+// an initializer.
+const tagNone byte = 0
+
+const (
+	tagBinary byte = iota + 1
+	tagUnary
+	tagGroup
+	tagLiteral
+	tagVariable
+	tagCall
+	tagAssign
+	tagListLiteral
+	tagMapLiteral
+	tagIndex
+	tagSetIndex
+	tagGet
+	tagExpressionStmt
+	tagPrintStmt
+	tagVarStmt
+	tagBlockStmt
+	tagForInStmt
+	tagIfStmt
+	tagThrowStmt
+	tagTryStmt
+)
+
+const (
+	literalNil byte = iota
+	literalFalse
+	literalTrue
+	literalConstant
+)
+
+// tokenTypesOnWire gives each token type the number it is written as. The
+// numbers are part of the format, so they must not follow the order the
+// TokenType constants happen to be declared in: a new token type takes the
+// next unused number, and CompiledVersion changes with it.
+var tokenTypesOnWire = map[TokenType]int{
+	LeftParen: 0, RightParen: 1, LeftBrace: 2, RightBrace: 3, LeftBracket: 4,
+	RightBracket: 5, Comma: 6, Dot: 7, Minus: 8, Plus: 9, Semicolon: 10,
+	Slash: 11, Star: 12, Colon: 13,
+
+	Bang: 14, BangEqual: 15, Equal: 16, EqualEqual: 17, Greater: 18,
+	GreaterEqual: 19, Less: 20, LessEqual: 21,
+
+	Identifier: 22, String: 23, Number: 24,
+
+	And: 25, Catch: 26, Class: 27, Else: 28, False: 29, Finally: 30, Fun: 31,
+	For: 32, If: 33, In: 34, Nil: 35, Or: 36, Print: 37, Return: 38, Super: 39,
+	This: 40, Throw: 41, True: 42, Try: 43, Var: 44, While: 45,
+
+	EOF: 46,
+}
+
+var tokenTypesFromWire = func() map[int]TokenType {
+	types := make(map[int]TokenType, len(tokenTypesOnWire))
+	for tokenType, n := range tokenTypesOnWire {
+		types[n] = tokenType
+	}
+	return types
+}()
+
+// IsCompiled reports whether data starts like a compiled program.
+func IsCompiled(data []byte) bool {
+	return bytes.HasPrefix(data, compiledMagic[:])
+}
+
+// WriteCompiled writes statements to w as a compiled program.
+func WriteCompiled(w io.Writer, statements []Stmt) error {
+	e := &compiledEncoder{constants: map[constantKey]int{}}
+	e.stmts(statements)
+
+	var out bytes.Buffer
+	out.Write(compiledMagic[:])
+	out.Write(binary.LittleEndian.AppendUint16(nil, CompiledVersion))
+
+	out.Write(binary.AppendUvarint(nil, uint64(len(e.pool))))
+	for _, c := range e.pool {
+		switch c := c.(type) {
+		case float64:
+			out.WriteByte(constNumber)
+			out.Write(binary.LittleEndian.AppendUint64(nil, math.Float64bits(c)))
+		case string:
+			out.WriteByte(constString)
+			out.Write(binary.AppendUvarint(nil, uint64(len(c))))
+			out.WriteString(c)
+		}
+	}
+
+	var runs [][2]int
+	for _, line := range e.lines {
+		if len(runs) > 0 && runs[len(runs)-1][0] == line {
+			runs[len(runs)-1][1]++
+			continue
+		}
+		runs = append(runs, [2]int{line, 1})
+	}
+	out.Write(binary.AppendUvarint(nil, uint64(len(runs))))
+	for _, run := range runs {
+		out.Write(binary.AppendUvarint(nil, uint64(run[0])))
+		out.Write(binary.AppendUvarint(nil, uint64(run[1])))
+	}
+
+	out.Write(e.code.Bytes())
+	out.Write(binary.LittleEndian.AppendUint32(nil, crc32.ChecksumIEEE(out.Bytes())))
+
+	_, err := w.Write(out.Bytes())
+	return err
+}
+
+// ReadCompiled reads a compiled program written by WriteCompiled. It returns
+// an error wrapping ErrNotCompiled, ErrCompiledVersion or ErrCompiledCorrupt
+// if r doesn't hold a program this version of glox can run.
+func ReadCompiled(r io.Reader) ([]Stmt, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	if !IsCompiled(data) {
+		return nil, ErrNotCompiled
+	}
+	if len(data) < len(compiledMagic)+2+4 {
+		return nil, fmt.Errorf("%w: truncated", ErrCompiledCorrupt)
+	}
+	if version := binary.LittleEndian.Uint16(data[len(compiledMagic):]); version != CompiledVersion {
+		return nil, fmt.Errorf("%w: the program was compiled to version %d, but this glox runs version %d; recompile it from source",
+			ErrCompiledVersion, version, CompiledVersion)
+	}
+	body, sum := data[:len(data)-4], binary.LittleEndian.Uint32(data[len(data)-4:])
+	if crc32.ChecksumIEEE(body) != sum {
+		return nil, fmt.Errorf("%w: checksum mismatch", ErrCompiledCorrupt)
+	}
+
+	d := &compiledDecoder{data: body, offset: len(compiledMagic) + 2}
+	statements := d.program()
+	if d.err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrCompiledCorrupt, d.err)
+	}
+	return statements, nil
+}
+
+type constantKey struct {
+	kind   byte
+	number uint64
+	str    string
+}
+
+type compiledEncoder struct {
+	code      bytes.Buffer
+	pool      []any
+	constants map[constantKey]int
+	lines     []int
+
+	// listing is set by Disassemble to collect an instruction for each node
+	// written. current is the instruction for the node being written.
+	listing *[]*instruction
+	current *instruction
+}
+
+func (e *compiledEncoder) uvarint(n int) {
+	e.code.Write(binary.AppendUvarint(nil, uint64(n)))
+}
+
+// operand adds an operand to the listing of the node being written.
+func (e *compiledEncoder) operand(format string, args ...any) {
+	if e.current != nil {
+		e.current.operands = append(e.current.operands, fmt.Sprintf(format, args...))
+	}
+}
+
+// constant returns the index of value in the constant pool, adding it if it
+// isn't there yet.
+func (e *compiledEncoder) constant(value any) int {
+	var key constantKey
+	switch v := value.(type) {
+	case float64:
+		key = constantKey{kind: constNumber, number: math.Float64bits(v)}
+	case string:
+		key = constantKey{kind: constString, str: v}
+	}
+	if idx, ok := e.constants[key]; ok {
+		return idx
+	}
+	e.pool = append(e.pool, value)
+	e.constants[key] = len(e.pool) - 1
+	return len(e.pool) - 1
+}
+
+func (e *compiledEncoder) token(t Token) {
+	e.uvarint(tokenTypesOnWire[t.TokenType])
+	lexeme := e.constant(t.Lexeme)
+	e.uvarint(lexeme)
+	switch t.Object.(type) {
+	case float64, string:
+		e.uvarint(e.constant(t.Object) + 1)
+	default:
+		e.uvarint(0)
+	}
+	e.uvarint(t.Column)
+	e.uvarint(t.Offset)
+	e.lines = append(e.lines, t.Line)
+	e.operand("#%d '%s'", lexeme, t.Lexeme)
+}
+
+func (e *compiledEncoder) origin(o origin) {
+	if o.from == nil {
+		e.code.WriteByte(0)
+		return
+	}
+	e.code.WriteByte(1)
+	e.position(o.from.Pos)
+	e.position(o.from.End)
+	e.uvarint(o.from.Line)
+	e.operand("from lines %d-%d", o.from.Pos.Line, o.from.End.Line)
+}
+
+func (e *compiledEncoder) position(p Position) {
+	e.uvarint(p.Line)
+	e.uvarint(p.Column)
+	e.uvarint(p.Offset)
+}
+
+// exprs and stmts write lists so that a missing list stays distinct from an
+// empty one.
+func (e *compiledEncoder) exprs(es []Expr) {
+	if es == nil {
+		e.uvarint(0)
+		return
+	}
+	e.uvarint(len(es) + 1)
+	for _, expr := range es {
+		e.node(expr)
+	}
+}
+
+func (e *compiledEncoder) stmts(ss []Stmt) {
+	if ss == nil {
+		e.uvarint(0)
+		return
+	}
+	e.uvarint(len(ss) + 1)
+	for _, stmt := range ss {
+		e.node(stmt)
+	}
+}
+
+func (e *compiledEncoder) literal(value any) {
+	switch v := value.(type) {
+	case bool:
+		if v {
+			e.code.WriteByte(literalTrue)
+		} else {
+			e.code.WriteByte(literalFalse)
+		}
+		e.operand("%t", v)
+	case float64, string:
+		idx := e.constant(v)
+		e.code.WriteByte(literalConstant)
+		e.uvarint(idx)
+		e.operand("#%d %s", idx, quote(v))
+	default:
+		e.code.WriteByte(literalNil)
+		e.operand("nil")
+	}
+}
+
+func (e *compiledEncoder) node(n Node) {
+	if e.listing != nil {
+		parent := e.current
+		e.current = &instruction{offset: e.code.Len(), node: n}
+		if parent != nil {
+			e.current.depth = parent.depth + 1
+		}
+		*e.listing = append(*e.listing, e.current)
+		defer func() { e.current = parent }()
+	}
+
+	switch n := n.(type) {
+	case nil:
+		e.code.WriteByte(tagNone)
+		return
+	case Binary:
+		e.code.WriteByte(tagBinary)
+		e.node(n.left)
+		e.token(n.operator)
+		e.node(n.right)
+		e.origin(n.origin)
+	case Unary:
+		e.code.WriteByte(tagUnary)
+		e.token(n.operator)
+		e.node(n.right)
+		e.origin(n.origin)
+	case Group:
+		e.code.WriteByte(tagGroup)
+		e.node(n.expr)
+		e.origin(n.origin)
+	case Literal:
+		e.code.WriteByte(tagLiteral)
+		e.literal(n.literal)
+		e.token(n.token)
+		e.origin(n.origin)
+	case Variable:
+		e.code.WriteByte(tagVariable)
+		e.token(n.name)
+		e.origin(n.origin)
+	case Call:
+		e.code.WriteByte(tagCall)
+		e.node(n.callee)
+		e.token(n.paren)
+		e.exprs(n.arguments)
+		e.origin(n.origin)
+	case Assign:
+		e.code.WriteByte(tagAssign)
+		e.token(n.name)
+		e.node(n.value)
+		e.origin(n.origin)
+	case ListLiteral:
+		e.code.WriteByte(tagListLiteral)
+		e.token(n.bracket)
+		e.exprs(n.elements)
+		e.token(n.closing)
+		e.origin(n.origin)
+	case MapLiteral:
+		e.code.WriteByte(tagMapLiteral)
+		e.token(n.brace)
+		e.exprs(n.keys)
+		e.exprs(n.values)
+		e.token(n.closing)
+		e.origin(n.origin)
+	case Index:
+		e.code.WriteByte(tagIndex)
+		e.node(n.object)
+		e.token(n.bracket)
+		e.node(n.index)
+		e.origin(n.origin)
+	case SetIndex:
+		e.code.WriteByte(tagSetIndex)
+		e.node(n.object)
+		e.token(n.bracket)
+		e.node(n.index)
+		e.node(n.value)
+		e.origin(n.origin)
+	case Get:
+		e.code.WriteByte(tagGet)
+		e.node(n.object)
+		e.token(n.name)
+		e.origin(n.origin)
+	case ExpressionStmt:
+		e.code.WriteByte(tagExpressionStmt)
+		e.node(n.expr)
+		e.origin(n.origin)
+	case PrintStmt:
+		e.code.WriteByte(tagPrintStmt)
+		e.token(n.keyword)
+		e.node(n.expr)
+		e.origin(n.origin)
+	case VarStmt:
+		e.code.WriteByte(tagVarStmt)
+		e.token(n.keyword)
+		e.token(n.name)
+		e.node(n.initializer)
+		e.origin(n.origin)
+	case BlockStmt:
+		e.code.WriteByte(tagBlockStmt)
+		e.token(n.brace)
+		e.stmts(n.statements)
+		e.token(n.closing)
+		e.origin(n.origin)
+	case ForInStmt:
+		e.code.WriteByte(tagForInStmt)
+		e.token(n.keyword)
+		e.token(n.name)
+		e.node(n.iterable)
+		e.node(n.body)
+		e.origin(n.origin)
+	case IfStmt:
+		e.code.WriteByte(tagIfStmt)
+		e.token(n.keyword)
+		e.node(n.condition)
+		e.node(n.thenBranch)
+		e.node(n.elseBranch)
+		e.origin(n.origin)
+	case ThrowStmt:
+		e.code.WriteByte(tagThrowStmt)
+		e.token(n.keyword)
+		e.node(n.value)
+		e.origin(n.origin)
+	case TryStmt:
+		e.code.WriteByte(tagTryStmt)
+		e.token(n.keyword)
+		e.stmts(n.body)
+		e.token(n.catchName)
+		e.stmts(n.catchBody)
+		e.stmts(n.finallyBody)
+		e.token(n.closing)
+		e.origin(n.origin)
+	default:
+		panic(fmt.Sprintf("lox: cannot compile node %T", n))
+	}
+}
+
+// compiledDecoder reads a compiled program. Once it runs into a problem it
+// records it in err and reads zeros from then on, so that the code reading
+// each node doesn't have to check for errors after every field.
+type compiledDecoder struct {
+	data      []byte
+	offset    int
+	constants []any
+	lines     []lineRun
+	depth     int
+	err       error
+}
+
+// lineRun is a run of count consecutive tokens on line.
+type lineRun struct {
+	line, count int
+}
+
+// maxCompiledDepth bounds how deeply the nodes of a compiled program may
+// nest, so that a corrupt or hostile file can't overflow the stack.
+const maxCompiledDepth = 10000
+
+func (d *compiledDecoder) fail(format string, args ...any) {
+	if d.err == nil {
+		d.err = fmt.Errorf(format, args...)
+	}
+}
+
+func (d *compiledDecoder) readByte() byte {
+	if d.err != nil {
+		return 0
+	}
+	if d.offset >= len(d.data) {
+		d.fail("truncated")
+		return 0
+	}
+	b := d.data[d.offset]
+	d.offset++
+	return b
+}
+
+func (d *compiledDecoder) uvarint() int {
+	if d.err != nil {
+		return 0
+	}
+	n, size := binary.Uvarint(d.data[d.offset:])
+	if size == 0 {
+		d.fail("truncated")
+		return 0
+	}
+	if size < 0 || n > math.MaxInt32 {
+		d.fail("invalid number at offset %d", d.offset)
+		return 0
+	}
+	d.offset += size
+	return int(n)
+}
+
+func (d *compiledDecoder) read(n int) []byte {
+	if d.err != nil {
+		return nil
+	}
+	if n > len(d.data)-d.offset {
+		d.fail("truncated")
+		return nil
+	}
+	b := d.data[d.offset : d.offset+n]
+	d.offset += n
+	return b
+}
+
+func (d *compiledDecoder) program() []Stmt {
+	count := d.uvarint()
+	for n := 0; n < count && d.err == nil; n++ {
+		switch kind := d.readByte(); kind {
+		case constNumber:
+			d.constants = append(d.constants, math.Float64frombits(binary.LittleEndian.Uint64(d.read(8))))
+		case constString:
+			d.constants = append(d.constants, string(d.read(d.uvarint())))
+		default:
+			d.fail("unknown constant kind %d", kind)
+		}
+	}
+
+	// Every token takes at least three bytes of code, so a line table with
+	// more tokens than that is corrupt, and would only waste memory.
+	runs, tokens := d.uvarint(), 0
+	for n := 0; n < runs && d.err == nil; n++ {
+		run := lineRun{line: d.uvarint(), count: d.uvarint()}
+		if tokens += run.count; tokens > (len(d.data)-d.offset)/3 {
+			d.fail("line table has more tokens than the code")
+		}
+		d.lines = append(d.lines, run)
+	}
+
+	statements := d.stmts()
+	if d.err == nil && d.offset != len(d.data) {
+		d.fail("%d unexpected bytes after the program", len(d.data)-d.offset)
+	}
+	return statements
+}
+
+func (d *compiledDecoder) constant(idx int) any {
+	if idx < 0 || idx >= len(d.constants) {
+		d.fail("constant %d out of range", idx)
+		return nil
+	}
+	return d.constants[idx]
+}
+
+func (d *compiledDecoder) lexeme() string {
+	s, ok := d.constant(d.uvarint()).(string)
+	if !ok {
+		d.fail("expected a string constant")
+	}
+	return s
+}
+
+func (d *compiledDecoder) token() Token {
+	tokenType, ok := tokenTypesFromWire[d.uvarint()]
+	if !ok && d.err == nil {
+		d.fail("unknown token type")
+	}
+	lexeme := d.lexeme()
+	var object any
+	if idx := d.uvarint(); idx > 0 {
+		object = d.constant(idx - 1)
+	}
+	column, offset := d.uvarint(), d.uvarint()
+	if d.err != nil {
+		return Token{}
+	}
+	for len(d.lines) > 0 && d.lines[0].count == 0 {
+		d.lines = d.lines[1:]
+	}
+	if len(d.lines) == 0 {
+		d.fail("line table is too short")
+		return Token{}
+	}
+	d.lines[0].count--
+	token := NewToken(tokenType, lexeme, object, d.lines[0].line)
+	token.Column, token.Offset = column, offset
+	return token
+}
+
+func (d *compiledDecoder) origin() origin {
+	if d.readByte() == 0 {
+		return origin{}
+	}
+	return origin{from: &Origin{Pos: d.position(), End: d.position(), Line: d.uvarint()}}
+}
+
+func (d *compiledDecoder) position() Position {
+	return Position{Line: d.uvarint(), Column: d.uvarint(), Offset: d.uvarint()}
+}
+
+func (d *compiledDecoder) literal() any {
+	switch kind := d.readByte(); kind {
+	case literalNil:
+		return nil
+	case literalFalse:
+		return false
+	case literalTrue:
+		return true
+	case literalConstant:
+		return d.constant(d.uvarint())
+	default:
+		d.fail("unknown literal kind %d", kind)
+		return nil
+	}
+}
+
+func (d *compiledDecoder) expr() Expr {
+	n := d.node()
+	if n == nil {
+		return nil
+	}
+	expr, ok := n.(Expr)
+	if !ok {
+		d.fail("expected an expression, got %T", n)
+	}
+	return expr
+}
+
+func (d *compiledDecoder) stmt() Stmt {
+	n := d.node()
+	if n == nil {
+		return nil
+	}
+	stmt, ok := n.(Stmt)
+	if !ok {
+		d.fail("expected a statement, got %T", n)
+	}
+	return stmt
+}
+
+func (d *compiledDecoder) exprs() []Expr {
+	count := d.uvarint()
+	if count == 0 {
+		return nil
+	}
+	es := []Expr{}
+	for n := 0; n < count-1 && d.err == nil; n++ {
+		es = append(es, d.expr())
+	}
+	return es
+}
+
+func (d *compiledDecoder) stmts() []Stmt {
+	count := d.uvarint()
+	if count == 0 {
+		return nil
+	}
+	ss := []Stmt{}
+	for n := 0; n < count-1 && d.err == nil; n++ {
+		ss = append(ss, d.stmt())
+	}
+	return ss
+}
+
+func (d *compiledDecoder) node() Node {
+	tag := d.readByte()
+	if d.err != nil {
+		return nil
+	}
+	if d.depth++; d.depth > maxCompiledDepth {
+		d.fail("nodes nested more than %d deep", maxCompiledDepth)
+		return nil
+	}
+	defer func() { d.depth-- }()
+
+	switch tag {
+	case tagNone:
+		return nil
+	case tagBinary:
+		n := Binary{left: d.expr(), operator: d.token(), right: d.expr()}
+		n.origin = d.origin()
+		return n
+	case tagUnary:
+		n := Unary{operator: d.token(), right: d.expr()}
+		n.origin = d.origin()
+		return n
+	case tagGroup:
+		n := Group{expr: d.expr()}
+		n.origin = d.origin()
+		return n
+	case tagLiteral:
+		n := Literal{literal: d.literal(), token: d.token()}
+		n.origin = d.origin()
+		return n
+	case tagVariable:
+		n := Variable{name: d.token()}
+		n.origin = d.origin()
+		return n
+	case tagCall:
+		n := Call{callee: d.expr(), paren: d.token(), arguments: d.exprs()}
+		n.origin = d.origin()
+		return n
+	case tagAssign:
+		n := Assign{name: d.token(), value: d.expr()}
+		n.origin = d.origin()
+		return n
+	case tagListLiteral:
+		n := ListLiteral{bracket: d.token(), elements: d.exprs(), closing: d.token()}
+		n.origin = d.origin()
+		return n
+	case tagMapLiteral:
+		n := MapLiteral{brace: d.token(), keys: d.exprs(), values: d.exprs(), closing: d.token()}
+		if len(n.keys) != len(n.values) {
+			d.fail("map literal with %d keys and %d values", len(n.keys), len(n.values))
+		}
+		n.origin = d.origin()
+		return n
+	case tagIndex:
+		n := Index{object: d.expr(), bracket: d.token(), index: d.expr()}
+		n.origin = d.origin()
+		return n
+	case tagSetIndex:
+		n := SetIndex{object: d.expr(), bracket: d.token(), index: d.expr(), value: d.expr()}
+		n.origin = d.origin()
+		return n
+	case tagGet:
+		n := Get{object: d.expr(), name: d.token()}
+		n.origin = d.origin()
+		return n
+	case tagExpressionStmt:
+		n := ExpressionStmt{expr: d.expr()}
+		n.origin = d.origin()
+		return n
+	case tagPrintStmt:
+		n := PrintStmt{keyword: d.token(), expr: d.expr()}
+		n.origin = d.origin()
+		return n
+	case tagVarStmt:
+		n := VarStmt{keyword: d.token(), name: d.token(), initializer: d.expr()}
+		n.origin = d.origin()
+		return n
+	case tagBlockStmt:
+		n := BlockStmt{brace: d.token(), statements: d.stmts(), closing: d.token()}
+		n.origin = d.origin()
+		return n
+	case tagForInStmt:
+		n := ForInStmt{keyword: d.token(), name: d.token(), iterable: d.expr(), body: d.stmt()}
+		n.origin = d.origin()
+		return n
+	case tagIfStmt:
+		n := IfStmt{keyword: d.token(), condition: d.expr(), thenBranch: d.stmt(), elseBranch: d.stmt()}
+		n.origin = d.origin()
+		return n
+	case tagThrowStmt:
+		n := ThrowStmt{keyword: d.token(), value: d.expr()}
+		n.origin = d.origin()
+		return n
+	case tagTryStmt:
+		n := TryStmt{keyword: d.token(), body: d.stmts(), catchName: d.token(), catchBody: d.stmts(), finallyBody: d.stmts(), closing: d.token()}
+		n.origin = d.origin()
+		return n
+	}
+	d.fail("unknown node tag %d", tag)
+	return nil
+}
@@ -0,0 +1,179 @@
+// ABOUTME: Tests for writing programs in the compiled .loxc format and reading them back
+// ABOUTME: Covers round trips, the file header and rejection of bad or mismatched files
+package lox
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash/crc32"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func compile(t *testing.T, statements []Stmt) []byte {
+	t.Helper()
+	var out bytes.Buffer
+	assert.NoError(t, WriteCompiled(&out, statements))
+	return out.Bytes()
+}
+
+// withChecksum replaces the checksum at the end of data with a correct one.
+func withChecksum(data []byte) []byte {
+	body := data[:len(data)-4]
+	return binary.LittleEndian.AppendUint32(bytes.Clone(body), crc32.ChecksumIEEE(body))
+}
+
+func TestCompiled_RoundTrip(t *testing.T) {
+	sources := []string{
+		"",
+		"1 + 2 * -3",
+		`var greeting = "hi" + nil; print greeting;`,
+		"var x; x = !true; (x);",
+		`var xs = [1, [2], f(3, g())]; xs[0] = xs[1][0]; print xs.len();`,
+		`var m = {"a": 1, 2: false}; {} { var y = m["a"]; }`,
+		"for x in xs { print x; }\nif (x) print 1;\n\nif (y) {} else print 2;",
+		`try { throw "boom"; } catch (e) { print e.message; } finally { print "done"; }`,
+		"try {} finally {}",
+		"print 1 >= 2 == 3 != 4 < 5 <= 6 > 7 - 8 / 9;",
+	}
+
+	for _, source := range sources {
+		t.Run(source, func(t *testing.T) {
+			asrt := assert.New(t)
+			statements := parseProgram(t, source)
+
+			decoded, err := ReadCompiled(bytes.NewReader(compile(t, statements)))
+			asrt.NoError(err)
+			asrt.Equal(statements, decoded)
+		})
+	}
+}
+
+func TestCompiled_OptimizedProgram(t *testing.T) {
+	asrt := assert.New(t)
+	statements := Optimize(parseProgram(t, "print 1 / 0;\nprint -(0 / 0);\nif (true)\n{ print (2 + 3) * x; }"))
+
+	decoded, err := ReadCompiled(bytes.NewReader(compile(t, statements)))
+	asrt.NoError(err)
+	asrt.Equal(statements[0], decoded[0])
+	asrt.True(math.IsNaN(decoded[1].(PrintStmt).Expression().(Literal).Value().(float64)))
+	asrt.Equal(statements[2], decoded[2])
+	asrt.Equal(Origin{Pos: Position{Line: 3, Column: 1, Offset: 29}, End: Position{Line: 4, Column: 23, Offset: 61}, Line: 4}, OriginOf(decoded[2]))
+}
+
+func TestCompiled_RunsLikeSource(t *testing.T) {
+	asrt := assert.New(t)
+	source := "var xs = [1, 2];\nfor x in xs print x * 10;\nprint xs[5];"
+
+	var out bytes.Buffer
+	decoded, err := ReadCompiled(bytes.NewReader(compile(t, parseProgram(t, source))))
+	asrt.NoError(err)
+	_, err = NewInterpreter(WithStdout(&out)).Execute(decoded)
+	asrt.Equal("10\n20\n", out.String())
+	asrt.EqualError(err, "[line 3] runtime error: list index 5 out of bounds for a list of length 2")
+}
+
+func TestCompiled_Header(t *testing.T) {
+	asrt := assert.New(t)
+	data := compile(t, parseProgram(t, `print "a"; print "a";`))
+
+	asrt.True(IsCompiled(data))
+	asrt.Equal([]byte("LOXC"), data[:4])
+	asrt.Equal(uint16(CompiledVersion), binary.LittleEndian.Uint16(data[4:]))
+	asrt.Equal(1, bytes.Count(data, []byte(`"a"`)), "repeated lexemes share a constant")
+	asrt.False(IsCompiled([]byte(`print "LOXC";`)))
+}
+
+// handwritten builds a compiled program from its constants, line table and
+// code, with a correct header and checksum.
+func handwritten(constants, lines, code []byte) []byte {
+	data := append([]byte("LOXC\x02\x00"), constants...)
+	data = append(append(data, lines...), code...)
+	return withChecksum(append(data, 0, 0, 0, 0))
+}
+
+func TestCompiled_Errors(t *testing.T) {
+	valid := compile(t, parseProgram(t, "print 1 + 2;"))
+	oneVariable := []byte{1, constString, 1, 'x'}
+	hugeRun := binary.AppendUvarint([]byte{1, 1}, math.MaxInt32)
+	deep := append([]byte{2, tagExpressionStmt}, bytes.Repeat([]byte{tagGroup}, maxCompiledDepth+1)...)
+	newerVersion := bytes.Clone(valid)
+	binary.LittleEndian.PutUint16(newerVersion[4:], CompiledVersion+1)
+	flipped := bytes.Clone(valid)
+	flipped[len(flipped)-6] ^= 0xff
+
+	tests := []struct {
+		name     string
+		data     []byte
+		target   error
+		errorMsg string
+	}{
+		{name: "source", data: []byte("print 1;"), target: ErrNotCompiled, errorMsg: "not a compiled Lox program"},
+		{
+			name:     "other version",
+			data:     newerVersion,
+			target:   ErrCompiledVersion,
+			errorMsg: "unsupported compiled Lox version: the program was compiled to version 3, but this glox runs version 2; recompile it from source",
+		},
+		{name: "header only", data: []byte("LOXC\x01"), target: ErrCompiledCorrupt, errorMsg: "corrupt compiled Lox program: truncated"},
+		{name: "bad checksum", data: flipped, target: ErrCompiledCorrupt, errorMsg: "corrupt compiled Lox program: checksum mismatch"},
+		{
+			name:     "truncated code",
+			data:     withChecksum(append(bytes.Clone(valid[:len(valid)-8]), 0, 0, 0, 0)),
+			target:   ErrCompiledCorrupt,
+			errorMsg: "corrupt compiled Lox program: truncated",
+		},
+		{
+			name:     "trailing bytes",
+			data:     withChecksum(append(bytes.Clone(valid[:len(valid)-4]), 7, 0, 0, 0, 0)),
+			target:   ErrCompiledCorrupt,
+			errorMsg: "corrupt compiled Lox program: 1 unexpected bytes after the program",
+		},
+		{
+			name:     "line table longer than the code",
+			data:     handwritten([]byte{0}, hugeRun, []byte{1}),
+			target:   ErrCompiledCorrupt,
+			errorMsg: "corrupt compiled Lox program: line table has more tokens than the code",
+		},
+		{
+			name:     "nested too deeply",
+			data:     handwritten([]byte{0}, []byte{0}, deep),
+			target:   ErrCompiledCorrupt,
+			errorMsg: "corrupt compiled Lox program: nodes nested more than 10000 deep",
+		},
+		{
+			name:     "unknown token type",
+			data:     handwritten(oneVariable, []byte{1, 1, 1}, []byte{2, tagExpressionStmt, tagVariable, 99, 0, 0, 0, 0}),
+			target:   ErrCompiledCorrupt,
+			errorMsg: "corrupt compiled Lox program: unknown token type",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ReadCompiled(bytes.NewReader(tt.data))
+			assert.ErrorIs(t, err, tt.target)
+			assert.EqualError(t, err, tt.errorMsg)
+		})
+	}
+}
+
+func TestCompiled_TokenTypesAreVersioned(t *testing.T) {
+	asrt := assert.New(t)
+
+	// tokenTypesAt is how many token types each version of the format has.
+	// Adding a token type means giving it a number in tokenTypesOnWire,
+	// bumping CompiledVersion and recording the new count here.
+	tokenTypesAt := map[int]int{1: 47, 2: 47}
+	declared := len(_TokenType_index) - 1
+	asrt.Equal(tokenTypesAt[CompiledVersion], declared, "the token types changed without a new CompiledVersion")
+
+	asrt.Len(tokenTypesOnWire, declared, "every token type needs a number on the wire")
+	asrt.Len(tokenTypesFromWire, declared, "no two token types may share a number")
+	for tokenType := range TokenType(declared) {
+		_, ok := tokenTypesOnWire[tokenType]
+		asrt.True(ok, "%s has no number on the wire", tokenType)
+	}
+}
@@ -0,0 +1,132 @@
+// ABOUTME: Conformance runner for the Lox programs under testdata, in the style of the standard test corpus
+// ABOUTME: Checks each program's output and errors against the expectation comments written in it
+package lox
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	expectOutput       = regexp.MustCompile(`// expect: ?(.*)`)
+	expectRuntimeError = regexp.MustCompile(`// expect runtime error: (.+)`)
+	expectSyntaxError  = regexp.MustCompile(`// \[line (\d+)\] (Error(?: at '.*'| at end)?: .+)`)
+)
+
+// transcript is what a program printed and the errors it stopped with, one
+// entry per line, in the forms the expectation comments use.
+type transcript struct {
+	output       []string
+	syntaxErrors []string
+	runtimeError string
+}
+
+func (t transcript) String() string {
+	var s strings.Builder
+	for _, line := range t.output {
+		fmt.Fprintf(&s, "expect: %s\n", line)
+	}
+	for _, line := range t.syntaxErrors {
+		fmt.Fprintf(&s, "%s\n", line)
+	}
+	if t.runtimeError != "" {
+		fmt.Fprintf(&s, "%s\n", t.runtimeError)
+	}
+	return s.String()
+}
+
+// expectations reads the transcript a program expects from its comments:
+//
+//	print 1; // expect: 1
+//	-"a"; // expect runtime error: operand to - must be a number
+//	// [line 3] Error at ';': expect expression
+//
+// A runtime error is expected on the line its comment is on.
+func expectations(source string) transcript {
+	var expected transcript
+	for idx, line := range strings.Split(source, "\n") {
+		if match := expectRuntimeError.FindStringSubmatch(line); match != nil {
+			expected.runtimeError = fmt.Sprintf("[line %d] %s", idx+1, match[1])
+		} else if match := expectSyntaxError.FindStringSubmatch(line); match != nil {
+			expected.syntaxErrors = append(expected.syntaxErrors, fmt.Sprintf("[line %s] %s", match[1], match[2]))
+		} else if match := expectOutput.FindStringSubmatch(line); match != nil {
+			expected.output = append(expected.output, match[1])
+		}
+	}
+	return expected
+}
+
+// runConformance runs source with RunScript, the way glox runs a script,
+// and records what happened. A program with syntax errors doesn't run.
+func runConformance(source string) transcript {
+	var actual transcript
+	var out bytes.Buffer
+	err := RunScript([]byte(source), &out, io.Discard)
+	if output := strings.TrimSuffix(out.String(), "\n"); output != "" {
+		actual.output = strings.Split(output, "\n")
+	}
+	for _, e := range Errors(err) {
+		if errors.Is(e, ErrLoxSyntax) {
+			where := ""
+			if e.Where != "" {
+				where = " " + e.Where
+			}
+			actual.syntaxErrors = append(actual.syntaxErrors, fmt.Sprintf("[line %d] Error%s: %s", e.Line, where, e.Message))
+		} else {
+			actual.runtimeError = fmt.Sprintf("[line %d] %s", e.Line, e.Message)
+		}
+	}
+	return actual
+}
+
+func TestConformance(t *testing.T) {
+	var files []string
+	err := filepath.WalkDir("testdata", func(path string, d fs.DirEntry, err error) error {
+		if err == nil && !d.IsDir() && filepath.Ext(path) == ".lox" {
+			files = append(files, path)
+		}
+		return err
+	})
+	assert.NoError(t, err)
+	assert.NotEmpty(t, files)
+
+	for _, file := range files {
+		name := strings.TrimSuffix(strings.TrimPrefix(filepath.ToSlash(file), "testdata/"), ".lox")
+		t.Run(name, func(t *testing.T) {
+			source, err := os.ReadFile(file)
+			assert.NoError(t, err)
+			assert.Equal(t, expectations(string(source)).String(), runConformance(string(source)).String())
+		})
+	}
+}
+
+func TestConformance_Expectations(t *testing.T) {
+	source := strings.Join([]string{
+		`print "a // b"; // expect: a // b`,
+		`print ""; // expect: `,
+		`// [line 4] Error at ';': expect expression`,
+		`// [line 5] Error at end: expect ';' after value`,
+		`// [line 6] Error: unexpected character`,
+		`-"a"; // expect runtime error: operand to - must be a number`,
+	}, "\n")
+
+	assert.Equal(t, transcript{
+		output: []string{"a // b", ""},
+		syntaxErrors: []string{
+			"[line 4] Error at ';': expect expression",
+			"[line 5] Error at end: expect ';' after value",
+			"[line 6] Error: unexpected character",
+		},
+		runtimeError: "[line 6] operand to - must be a number",
+	}, expectations(source))
+}
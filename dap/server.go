@@ -0,0 +1,397 @@
+package dap
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+
+	lox "github.com/mikowitz/glox"
+	"github.com/mikowitz/glox/internal/framing"
+)
+
+const (
+	threadID = 1
+	// localsReference is the variables reference of the innermost scope.
+	// Scopes further out are numbered from it outward, ending with the
+	// globals.
+	localsReference = 1
+	// exitRuntimeError matches the exit status the glox CLI uses when a
+	// script fails at runtime.
+	exitRuntimeError = 70
+)
+
+// Server speaks the Debug Adapter Protocol over a pair of streams, usually the
+// process's stdin and stdout. It debugs a single Lox program per session,
+// evaluating it on its own goroutine so requests can be served while the
+// program is paused.
+type Server struct {
+	in       *bufio.Reader
+	debugger *lox.Debugger
+	resume   chan func(*lox.Debugger)
+
+	program     string
+	statements  []lox.Stmt
+	breakable   map[int]bool
+	interpreter *lox.Interpreter
+	started     bool
+
+	// mu guards everything below, which is shared with the goroutine
+	// evaluating the program.
+	mu     sync.Mutex
+	out    io.Writer
+	seq    int
+	frames []lox.Frame
+}
+
+func NewServer(in io.Reader, out io.Writer) *Server {
+	s := &Server{
+		in:     bufio.NewReader(in),
+		out:    out,
+		resume: make(chan func(*lox.Debugger), 1),
+	}
+	s.debugger = lox.NewDebugger(s.stopped)
+	return s
+}
+
+// Run serves requests until the client disconnects or closes the input stream.
+func (s *Server) Run() error {
+	for {
+		body, err := framing.Read(s.in)
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		var req request
+		if err := json.Unmarshal(body, &req); err != nil {
+			// The message is framed correctly, so the next one can still
+			// be read. Without a request to answer, the error goes back
+			// unattributed.
+			if err := s.fail(req, fmt.Errorf("malformed request: %w", err)); err != nil {
+				return err
+			}
+			continue
+		}
+
+		done, err := s.handle(req)
+		if err != nil || done {
+			return err
+		}
+	}
+}
+
+func (s *Server) handle(req request) (bool, error) {
+	switch req.Command {
+	case "initialize":
+		err := s.respond(req, map[string]any{
+			"supportsConfigurationDoneRequest": true,
+		})
+		if err != nil {
+			return false, err
+		}
+		return false, s.sendEvent("initialized", nil)
+	case "launch":
+		if s.started {
+			// The program is evaluating with the current interpreter.
+			return false, s.fail(req, errors.New("the program is already running"))
+		}
+		var args launchArguments
+		if err := json.Unmarshal(req.Arguments, &args); err != nil {
+			return false, s.fail(req, err)
+		}
+		if err := s.load(args); err != nil {
+			return false, s.fail(req, err)
+		}
+		return false, s.respond(req, nil)
+	case "setBreakpoints":
+		var args setBreakpointsArguments
+		if err := json.Unmarshal(req.Arguments, &args); err != nil {
+			return false, s.fail(req, err)
+		}
+		s.debugger.ClearBreakpoints()
+		breakpoints := []Breakpoint{}
+		for _, bp := range args.Breakpoints {
+			// The debugger only stops before expressions, so a breakpoint
+			// on a line without one would never be hit.
+			if !s.breakable[bp.Line] {
+				breakpoints = append(breakpoints, Breakpoint{Line: bp.Line, Message: "no expression on this line to stop at"})
+				continue
+			}
+			s.debugger.SetBreakpoint(bp.Line)
+			breakpoints = append(breakpoints, Breakpoint{Verified: true, Line: bp.Line})
+		}
+		return false, s.respond(req, map[string]any{"breakpoints": breakpoints})
+	case "configurationDone":
+		if s.statements == nil {
+			return false, s.fail(req, errors.New("no program has been launched"))
+		}
+		if s.started {
+			return false, s.fail(req, errors.New("the program is already running"))
+		}
+		if err := s.respond(req, nil); err != nil {
+			return false, err
+		}
+		s.started = true
+		go s.execute()
+		return false, nil
+	case "threads":
+		return false, s.respond(req, map[string]any{
+			"threads": []Thread{{ID: threadID, Name: "main"}},
+		})
+	case "stackTrace":
+		return false, s.respond(req, s.stackTrace())
+	case "scopes":
+		return false, s.respond(req, map[string]any{"scopes": s.scopes()})
+	case "variables":
+		var args variablesArguments
+		if err := json.Unmarshal(req.Arguments, &args); err != nil {
+			return false, s.fail(req, err)
+		}
+		return false, s.respond(req, map[string]any{"variables": s.variables(args.VariablesReference)})
+	case "continue":
+		return false, s.resumeWith(req, (*lox.Debugger).Continue, map[string]any{"allThreadsContinued": true})
+	case "next":
+		return false, s.resumeWith(req, (*lox.Debugger).Next, nil)
+	case "stepIn":
+		return false, s.resumeWith(req, (*lox.Debugger).Step, nil)
+	case "stepOut":
+		return false, s.resumeWith(req, (*lox.Debugger).Finish, nil)
+	case "pause":
+		s.debugger.Pause()
+		return false, s.respond(req, nil)
+	case "disconnect", "terminate":
+		// Let the program run to completion without stopping again.
+		s.debugger.ClearBreakpoints()
+		if err := s.resumeWith(req, (*lox.Debugger).Continue, nil); err != nil {
+			return false, err
+		}
+		return true, nil
+	}
+
+	return false, s.fail(req, fmt.Errorf("unsupported command: %s", req.Command))
+}
+
+func (s *Server) load(args launchArguments) error {
+	bytes, err := os.ReadFile(args.Program)
+	if err != nil {
+		return err
+	}
+
+	scanner := lox.NewScanner(string(bytes))
+	tokens, err := scanner.ScanTokens()
+	if err != nil {
+		return err
+	}
+
+	parser := lox.NewParser(tokens)
+	statements, err := parser.ParseProgram()
+	if err != nil {
+		return err
+	}
+
+	s.program = args.Program
+	s.statements = statements
+	s.breakable = lox.BreakableLines(statements)
+	s.interpreter = lox.NewInterpreter(
+		lox.WithStdout(outputWriter{s, "stdout"}),
+		lox.WithStderr(outputWriter{s, "stderr"}),
+	)
+	if !args.NoDebug {
+		s.interpreter.SetHook(s.debugger)
+	}
+	if !args.StopOnEntry {
+		s.debugger.Continue()
+	}
+	return nil
+}
+
+func (s *Server) execute() {
+	exitCode := 0
+	result, err := s.interpreter.Execute(s.statements)
+	if err != nil {
+		exitCode = exitRuntimeError
+		// Written in one call so the client gets a single output event.
+		fmt.Fprint(s.interpreter.Stderr(), err.Error()+"\n"+lox.StackTrace(err))
+	} else if result != nil {
+		fmt.Fprintln(s.interpreter.Stdout(), lox.Stringify(result))
+	}
+
+	// The client may already have gone away, so failures are ignored.
+	_ = s.sendEvent("exited", map[string]any{"exitCode": exitCode})
+	_ = s.sendEvent("terminated", nil)
+}
+
+// stopped is called on the evaluating goroutine whenever the debugger pauses.
+// It blocks until a request tells the debugger how to resume.
+func (s *Server) stopped(reason lox.StopReason, frames []lox.Frame) {
+	s.mu.Lock()
+	s.frames = slices.Clone(frames)
+	s.mu.Unlock()
+
+	_ = s.sendEvent("stopped", map[string]any{
+		"reason":            string(reason),
+		"threadId":          threadID,
+		"allThreadsStopped": true,
+	})
+
+	resume := <-s.resume
+	resume(s.debugger)
+}
+
+func (s *Server) resumeWith(req request, resume func(*lox.Debugger), body any) error {
+	if err := s.respond(req, body); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	paused := s.frames != nil
+	s.frames = nil
+	s.mu.Unlock()
+	if paused {
+		s.resume <- resume
+	}
+	return nil
+}
+
+// stackTrace lists the expressions being evaluated, innermost first. Lox
+// has no functions yet, so there are no calls to show; each frame is an
+// expression, named as one, that encloses the frame before it.
+func (s *Server) stackTrace() map[string]any {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stack := []StackFrame{}
+	printer := &lox.AstPrinter{}
+	source := Source{Name: filepath.Base(s.program), Path: s.program}
+	for depth := len(s.frames) - 1; depth >= 0; depth-- {
+		frame := s.frames[depth]
+		stack = append(stack, StackFrame{
+			ID:     depth,
+			Name:   "expression " + printer.Print(frame.Expr),
+			Source: source,
+			Line:   frame.Line,
+			Column: 1,
+		})
+	}
+	return map[string]any{"stackFrames": stack, "totalFrames": len(stack)}
+}
+
+// environments lists the environments in scope, innermost first and ending
+// with the globals. Environments are only read while the program is paused,
+// when the evaluating goroutine is blocked in stopped, so callers must hold
+// s.mu and check that it is.
+func (s *Server) environments() []*lox.Environment {
+	var envs []*lox.Environment
+	globals := s.interpreter.Globals()
+	for env := s.interpreter.Environment(); env != nil && env != globals; env = env.Enclosing() {
+		envs = append(envs, env)
+	}
+	return append(envs, globals)
+}
+
+// scopes lists the scopes in which variables can be seen: the innermost
+// local scope, each scope enclosing it and the globals. Variable references
+// number them in that order, from localsReference.
+func (s *Server) scopes() []Scope {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	scopes := []Scope{}
+	if s.frames == nil {
+		return scopes
+	}
+	envs := s.environments()
+	for idx := range envs {
+		name := "Locals"
+		switch {
+		case idx == len(envs)-1:
+			name = "Globals"
+		case idx > 0:
+			name = fmt.Sprintf("Enclosing %d", idx)
+		}
+		scopes = append(scopes, Scope{Name: name, VariablesReference: localsReference + idx})
+	}
+	return scopes
+}
+
+// variables lists the bindings in the scope numbered reference by scopes.
+func (s *Server) variables(reference int) []Variable {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	variables := []Variable{}
+	if s.frames == nil {
+		return variables
+	}
+	envs := s.environments()
+	if reference < localsReference || reference-localsReference >= len(envs) {
+		return variables
+	}
+	env := envs[reference-localsReference]
+	for _, name := range env.Names() {
+		value, _ := env.Lookup(name)
+		variables = append(variables, Variable{
+			Name:  name,
+			Value: lox.Stringify(value),
+			Type:  lox.TypeOf(value),
+		})
+	}
+	return variables
+}
+
+// outputWriter forwards program output to the client as output events.
+type outputWriter struct {
+	server   *Server
+	category string
+}
+
+func (w outputWriter) Write(p []byte) (int, error) {
+	err := w.server.sendEvent("output", map[string]any{"category": w.category, "output": string(p)})
+	if err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+func (s *Server) respond(req request, body any) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.seq++
+	return framing.Write(s.out, response{
+		Seq:        s.seq,
+		Type:       "response",
+		RequestSeq: req.Seq,
+		Success:    true,
+		Command:    req.Command,
+		Body:       body,
+	})
+}
+
+func (s *Server) fail(req request, err error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.seq++
+	return framing.Write(s.out, response{
+		Seq:        s.seq,
+		Type:       "response",
+		RequestSeq: req.Seq,
+		Success:    false,
+		Command:    req.Command,
+		Message:    err.Error(),
+	})
+}
+
+func (s *Server) sendEvent(name string, body any) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.seq++
+	return framing.Write(s.out, event{Seq: s.seq, Type: "event", Event: name, Body: body})
+}
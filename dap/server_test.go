@@ -0,0 +1,361 @@
+// ABOUTME: Tests for the debug adapter, driven by a fake DAP client in-process
+// ABOUTME: Covers launching, breakpoints, stepping, stack traces, scopes, bad requests and termination
+package dap
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/mikowitz/glox/internal/framing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type incoming struct {
+	Type       string          `json:"type"`
+	Event      string          `json:"event"`
+	Command    string          `json:"command"`
+	RequestSeq int             `json:"request_seq"`
+	Success    bool            `json:"success"`
+	Message    string          `json:"message"`
+	Body       json.RawMessage `json:"body"`
+}
+
+// testClient drives a Server over in-memory pipes. Messages from the server
+// are read on a separate goroutine and events are queued until a test waits
+// for them.
+type testClient struct {
+	t        *testing.T
+	w        io.WriteCloser
+	incoming chan incoming
+	events   []incoming
+	seq      int
+	done     chan error
+}
+
+func newTestClient(t *testing.T) *testClient {
+	clientR, serverW := io.Pipe()
+	serverR, clientW := io.Pipe()
+
+	c := &testClient{
+		t:        t,
+		w:        clientW,
+		incoming: make(chan incoming, 64),
+		done:     make(chan error, 1),
+	}
+	go func() {
+		r := bufio.NewReader(clientR)
+		for {
+			body, err := framing.Read(r)
+			if err != nil {
+				close(c.incoming)
+				return
+			}
+			var msg incoming
+			if json.Unmarshal(body, &msg) == nil {
+				c.incoming <- msg
+			}
+		}
+	}()
+	go func() {
+		c.done <- NewServer(serverR, serverW).Run()
+		serverW.Close()
+	}()
+	t.Cleanup(func() { clientW.Close() })
+	return c
+}
+
+func (c *testClient) next() incoming {
+	select {
+	case msg, ok := <-c.incoming:
+		require.True(c.t, ok, "server closed the connection")
+		return msg
+	case <-time.After(5 * time.Second):
+		c.t.Fatal("timed out waiting for the server")
+		return incoming{}
+	}
+}
+
+func (c *testClient) request(command string, args any) incoming {
+	c.seq++
+	raw, err := json.Marshal(args)
+	require.NoError(c.t, err)
+	require.NoError(c.t, framing.Write(c.w, request{Seq: c.seq, Type: "request", Command: command, Arguments: raw}))
+
+	for {
+		msg := c.next()
+		if msg.Type == "event" {
+			c.events = append(c.events, msg)
+			continue
+		}
+		require.Equal(c.t, c.seq, msg.RequestSeq)
+		require.Equal(c.t, command, msg.Command)
+		return msg
+	}
+}
+
+func (c *testClient) waitEvent(name string) incoming {
+	for len(c.events) > 0 {
+		msg := c.events[0]
+		c.events = c.events[1:]
+		if msg.Event == name {
+			return msg
+		}
+	}
+	for {
+		msg := c.next()
+		if msg.Type == "event" && msg.Event == name {
+			return msg
+		}
+	}
+}
+
+func (c *testClient) stopped() (string, []StackFrame) {
+	var stop struct {
+		Reason string `json:"reason"`
+	}
+	require.NoError(c.t, json.Unmarshal(c.waitEvent("stopped").Body, &stop))
+
+	var trace struct {
+		StackFrames []StackFrame `json:"stackFrames"`
+	}
+	resp := c.request("stackTrace", map[string]any{"threadId": threadID})
+	require.NoError(c.t, json.Unmarshal(resp.Body, &trace))
+	return stop.Reason, trace.StackFrames
+}
+
+func (c *testClient) launch(source string, stopOnEntry bool, breakpoints ...int) string {
+	program := filepath.Join(c.t.TempDir(), "test.lox")
+	require.NoError(c.t, os.WriteFile(program, []byte(source), 0o644))
+
+	resp := c.request("initialize", map[string]any{"adapterID": "glox"})
+	require.True(c.t, resp.Success)
+	c.waitEvent("initialized")
+
+	resp = c.request("launch", map[string]any{"program": program, "stopOnEntry": stopOnEntry})
+	require.True(c.t, resp.Success, resp.Message)
+
+	lines := []map[string]int{}
+	for _, line := range breakpoints {
+		lines = append(lines, map[string]int{"line": line})
+	}
+	resp = c.request("setBreakpoints", map[string]any{
+		"source":      map[string]any{"path": program},
+		"breakpoints": lines,
+	})
+	require.True(c.t, resp.Success)
+
+	resp = c.request("configurationDone", nil)
+	require.True(c.t, resp.Success)
+	return program
+}
+
+func (c *testClient) output() (string, string) {
+	var out struct {
+		Category string `json:"category"`
+		Output   string `json:"output"`
+	}
+	require.NoError(c.t, json.Unmarshal(c.waitEvent("output").Body, &out))
+	return out.Category, out.Output
+}
+
+const script = "(1 +\n  2) *\n-3"
+
+func TestServer_StopOnEntryAndStep(t *testing.T) {
+	asrt := assert.New(t)
+	client := newTestClient(t)
+	program := client.launch(script, true)
+
+	reason, frames := client.stopped()
+	asrt.Equal("entry", reason)
+	asrt.Equal([]StackFrame{
+		{ID: 0, Name: "expression (* (group (+ 1 2)) (- 3))", Source: Source{Name: "test.lox", Path: program}, Line: 2, Column: 1},
+	}, frames)
+
+	client.request("stepIn", map[string]any{"threadId": threadID})
+	reason, frames = client.stopped()
+	asrt.Equal("step", reason)
+	asrt.Len(frames, 2)
+	asrt.Equal("expression (group (+ 1 2))", frames[0].Name)
+	asrt.Equal(1, frames[0].Line)
+
+	client.request("next", map[string]any{"threadId": threadID})
+	reason, frames = client.stopped()
+	asrt.Equal("step", reason)
+	asrt.Equal("expression (- 3)", frames[0].Name)
+	asrt.Equal(3, frames[0].Line)
+
+	client.request("stepOut", map[string]any{"threadId": threadID})
+	category, output := client.output()
+	asrt.Equal("stdout", category)
+	asrt.Equal("-9\n", output)
+	client.waitEvent("terminated")
+}
+
+func TestServer_Breakpoints(t *testing.T) {
+	asrt := assert.New(t)
+	client := newTestClient(t)
+	client.launch(script, false, 3)
+
+	reason, frames := client.stopped()
+	asrt.Equal("breakpoint", reason)
+	asrt.Equal(3, frames[0].Line)
+	asrt.Equal("expression (- 3)", frames[0].Name)
+	asrt.Equal("expression (* (group (+ 1 2)) (- 3))", frames[1].Name)
+
+	resp := client.request("scopes", map[string]any{"frameId": frames[0].ID})
+	asrt.JSONEq(`{"scopes":[{"name":"Globals","variablesReference":1,"expensive":false}]}`, string(resp.Body))
+	resp = client.request("variables", map[string]any{"variablesReference": localsReference})
+	asrt.JSONEq(`{"variables":[]}`, string(resp.Body))
+
+	resp = client.request("threads", nil)
+	asrt.JSONEq(`{"threads":[{"id":1,"name":"main"}]}`, string(resp.Body))
+
+	client.request("continue", map[string]any{"threadId": threadID})
+	_, output := client.output()
+	asrt.Equal("-9\n", output)
+	client.waitEvent("exited")
+	client.waitEvent("terminated")
+
+	client.request("disconnect", nil)
+	asrt.NoError(<-client.done)
+}
+
+func TestServer_BreakpointsWithoutExpressions(t *testing.T) {
+	asrt := assert.New(t)
+	client := newTestClient(t)
+	program := client.launch("var x;\n{\nx = 1;\n}", true)
+	client.stopped()
+
+	resp := client.request("setBreakpoints", map[string]any{
+		"source":      map[string]any{"path": program},
+		"breakpoints": []map[string]int{{"line": 1}, {"line": 3}, {"line": 4}},
+	})
+	asrt.JSONEq(`{"breakpoints":[
+		{"verified":false,"line":1,"message":"no expression on this line to stop at"},
+		{"verified":true,"line":3},
+		{"verified":false,"line":4,"message":"no expression on this line to stop at"}
+	]}`, string(resp.Body))
+
+	client.request("continue", map[string]any{"threadId": threadID})
+	client.waitEvent("terminated")
+}
+
+func TestServer_LaunchWhileRunning(t *testing.T) {
+	asrt := assert.New(t)
+	client := newTestClient(t)
+	client.launch(script, true)
+	client.stopped()
+
+	other := filepath.Join(t.TempDir(), "other.lox")
+	asrt.NoError(os.WriteFile(other, []byte("print 1;"), 0o644))
+	resp := client.request("launch", map[string]any{"program": other})
+	asrt.False(resp.Success)
+	asrt.Equal("the program is already running", resp.Message)
+
+	client.request("continue", map[string]any{"threadId": threadID})
+	_, output := client.output()
+	asrt.Equal("-9\n", output, "the first program runs to completion")
+	client.waitEvent("terminated")
+}
+
+func TestServer_Scopes(t *testing.T) {
+	asrt := assert.New(t)
+	client := newTestClient(t)
+	client.launch("var g = \"global\";\n{\n  var a = 1;\n  {\n    var b = [a];\n    print b;\n  }\n}", false, 6)
+
+	_, frames := client.stopped()
+	resp := client.request("scopes", map[string]any{"frameId": frames[0].ID})
+	asrt.JSONEq(`{"scopes":[
+		{"name":"Locals","variablesReference":1,"expensive":false},
+		{"name":"Enclosing 1","variablesReference":2,"expensive":false},
+		{"name":"Globals","variablesReference":3,"expensive":false}
+	]}`, string(resp.Body))
+
+	tests := []struct {
+		reference int
+		expected  string
+	}{
+		{reference: 1, expected: `{"variables":[{"name":"b","value":"[1]","type":"list","variablesReference":0}]}`},
+		{reference: 2, expected: `{"variables":[{"name":"a","value":"1","type":"number","variablesReference":0}]}`},
+		{reference: 3, expected: `{"variables":[{"name":"g","value":"global","type":"string","variablesReference":0}]}`},
+		{reference: 4, expected: `{"variables":[]}`},
+	}
+	for _, tt := range tests {
+		resp = client.request("variables", map[string]any{"variablesReference": tt.reference})
+		asrt.JSONEq(tt.expected, string(resp.Body), "reference %d", tt.reference)
+	}
+
+	client.request("continue", map[string]any{"threadId": threadID})
+	client.waitEvent("terminated")
+}
+
+func TestServer_ConfigurationDoneTwice(t *testing.T) {
+	asrt := assert.New(t)
+	client := newTestClient(t)
+	client.launch(script, true)
+	client.stopped()
+
+	resp := client.request("configurationDone", nil)
+	asrt.False(resp.Success)
+	asrt.Equal("the program is already running", resp.Message)
+
+	client.request("continue", map[string]any{"threadId": threadID})
+	_, output := client.output()
+	asrt.Equal("-9\n", output)
+	client.waitEvent("terminated")
+}
+
+func TestServer_MalformedRequest(t *testing.T) {
+	asrt := assert.New(t)
+	client := newTestClient(t)
+
+	_, err := io.WriteString(client.w, "Content-Length: 9\r\n\r\n{\"seq\": }")
+	asrt.NoError(err)
+	resp := client.next()
+	asrt.Equal("response", resp.Type)
+	asrt.False(resp.Success)
+	asrt.Contains(resp.Message, "malformed request")
+
+	resp = client.request("threads", nil)
+	asrt.True(resp.Success, "the session carries on")
+}
+
+func TestServer_RuntimeError(t *testing.T) {
+	asrt := assert.New(t)
+	client := newTestClient(t)
+	client.launch(`1 + "a"`, false)
+
+	category, output := client.output()
+	asrt.Equal("stderr", category)
+	asrt.Contains(output, "operands to + must both be numbers or strings")
+
+	var exited struct {
+		ExitCode int `json:"exitCode"`
+	}
+	asrt.NoError(json.Unmarshal(client.waitEvent("exited").Body, &exited))
+	asrt.Equal(exitRuntimeError, exited.ExitCode)
+}
+
+func TestServer_LaunchErrors(t *testing.T) {
+	asrt := assert.New(t)
+	client := newTestClient(t)
+	client.request("initialize", nil)
+
+	resp := client.request("launch", map[string]any{"program": filepath.Join(t.TempDir(), "missing.lox")})
+	asrt.False(resp.Success)
+
+	program := filepath.Join(t.TempDir(), "bad.lox")
+	asrt.NoError(os.WriteFile(program, []byte("(1 +"), 0o644))
+	resp = client.request("launch", map[string]any{"program": program})
+	asrt.False(resp.Success)
+	asrt.Contains(resp.Message, "expect expression")
+
+	resp = client.request("configurationDone", nil)
+	asrt.False(resp.Success)
+}
@@ -0,0 +1,208 @@
+package lox
+
+import "sync"
+
+// Frame is an expression the interpreter has started but not yet finished
+// evaluating, along with the source line it belongs to.
+type Frame struct {
+	Expr Expr
+	Line int
+}
+
+// Hook is called by the interpreter before it evaluates each expression.
+// frames is the evaluation stack, outermost first; the last frame is the
+// expression about to be evaluated. The slice is only valid for the duration
+// of the call.
+type Hook interface {
+	BeforeExpr(frames []Frame)
+}
+
+type StopReason string
+
+const (
+	StopEntry      StopReason = "entry"
+	StopStep       StopReason = "step"
+	StopBreakpoint StopReason = "breakpoint"
+	StopPause      StopReason = "pause"
+)
+
+type stepMode int
+
+const (
+	modeContinue stepMode = iota
+	modeStep
+	modeNext
+	modeFinish
+)
+
+// Debugger is a Hook that pauses evaluation at line breakpoints and after
+// step commands. When it pauses it calls onStop synchronously; evaluation
+// resumes when onStop returns, using whichever of Continue, Step, Next or
+// Finish was called last. Its methods are safe to call from other goroutines
+// while the interpreter is running.
+type Debugger struct {
+	mu          sync.Mutex
+	breakpoints map[int]bool
+	mode        stepMode
+	depth       int
+	lastLine    int
+	started     bool
+	pausing     bool
+	onStop      func(reason StopReason, frames []Frame)
+}
+
+// NewDebugger returns a Debugger that pauses before the first expression.
+func NewDebugger(onStop func(reason StopReason, frames []Frame)) *Debugger {
+	return &Debugger{
+		breakpoints: map[int]bool{},
+		mode:        modeStep,
+		onStop:      onStop,
+	}
+}
+
+// BreakableLines reports the lines of statements a Debugger can stop at,
+// which are the lines some expression belongs to. The debugger only stops
+// before expressions, so a breakpoint on any other line, such as one holding
+// only a brace or a var statement without an initializer, is never hit.
+func BreakableLines(statements []Stmt) map[int]bool {
+	lines := map[int]bool{}
+	for _, stmt := range statements {
+		Walk(stmt, func(n Node) bool {
+			if e, ok := n.(Expr); ok {
+				lines[lineOf(e)] = true
+			}
+			return true
+		})
+	}
+	return lines
+}
+
+func (d *Debugger) SetBreakpoint(line int) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.breakpoints[line] = true
+}
+
+func (d *Debugger) ClearBreakpoint(line int) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	delete(d.breakpoints, line)
+}
+
+// ClearBreakpoints removes every breakpoint.
+func (d *Debugger) ClearBreakpoints() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	clear(d.breakpoints)
+}
+
+func (d *Debugger) Continue() {
+	d.setMode(modeContinue)
+}
+
+// Step pauses at the very next expression, including ones nested inside the
+// current expression.
+func (d *Debugger) Step() {
+	d.setMode(modeStep)
+}
+
+// Next pauses at the next expression that is not nested inside the current
+// one.
+func (d *Debugger) Next() {
+	d.setMode(modeNext)
+}
+
+// Finish pauses at the next expression outside the one enclosing the current
+// expression.
+func (d *Debugger) Finish() {
+	d.setMode(modeFinish)
+}
+
+// Pause asks a running interpreter to stop at the next expression.
+func (d *Debugger) Pause() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.pausing = true
+}
+
+func (d *Debugger) setMode(mode stepMode) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.mode = mode
+}
+
+func (d *Debugger) BeforeExpr(frames []Frame) {
+	reason, ok := d.shouldStop(frames)
+	if !ok {
+		return
+	}
+
+	d.onStop(reason, frames)
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.depth = len(frames)
+}
+
+func (d *Debugger) shouldStop(frames []Frame) (StopReason, bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	depth := len(frames)
+	line := frames[depth-1].Line
+	enteredLine := line != d.lastLine
+	d.lastLine = line
+
+	var reason StopReason
+	switch {
+	case d.pausing:
+		d.pausing = false
+		reason = StopPause
+	case d.mode == modeStep && !d.started:
+		reason = StopEntry
+	case d.mode == modeStep,
+		d.mode == modeNext && depth <= d.depth,
+		d.mode == modeFinish && depth < d.depth:
+		reason = StopStep
+	case enteredLine && d.breakpoints[line]:
+		reason = StopBreakpoint
+	default:
+		return "", false
+	}
+
+	d.started = true
+	return reason, true
+}
+
+// lineOf reports the source line of an expression, which is the line of its
+// operator or, for groups, of the expression inside them. Expressions that
+// replaced others in a rewrite report the line of what they replaced.
+func lineOf(e Expr) int {
+	switch e := e.(type) {
+	case Binary:
+		return e.lineOr(e.operator.Line)
+	case Unary:
+		return e.lineOr(e.operator.Line)
+	case Group:
+		return e.lineOr(lineOf(e.expr))
+	case Literal:
+		return e.lineOr(e.token.Line)
+	case Variable:
+		return e.lineOr(e.name.Line)
+	case Call:
+		return e.lineOr(e.paren.Line)
+	case Assign:
+		return e.lineOr(e.name.Line)
+	case ListLiteral:
+		return e.lineOr(e.bracket.Line)
+	case MapLiteral:
+		return e.lineOr(e.brace.Line)
+	case Index:
+		return e.lineOr(e.bracket.Line)
+	case SetIndex:
+		return e.lineOr(e.bracket.Line)
+	case Get:
+		return e.lineOr(e.name.Line)
+	}
+	return 0
+}
@@ -0,0 +1,170 @@
+// ABOUTME: Tests for the interpreter's debug hook and the stepping debugger
+// ABOUTME: Drives the debugger with scripted commands and records where it stops
+package lox
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func parseSource(t *testing.T, source string) Expr {
+	t.Helper()
+	tokens, err := NewScanner(source).ScanTokens()
+	assert.NoError(t, err)
+	expr, err := NewParser(tokens).Parse()
+	assert.NoError(t, err)
+	return expr
+}
+
+func TestDebugger_Stops(t *testing.T) {
+	source := "(1 +\n  2) *\n-3"
+
+	tests := []struct {
+		name        string
+		breakpoints []int
+		commands    []func(d *Debugger)
+		expected    []string
+	}{
+		{
+			name:     "continue from entry",
+			commands: []func(*Debugger){(*Debugger).Continue},
+			expected: []string{"entry 2 (* (group (+ 1 2)) (- 3))"},
+		},
+		{
+			name: "step visits every expression",
+			commands: []func(*Debugger){
+				(*Debugger).Step, (*Debugger).Step, (*Debugger).Step,
+				(*Debugger).Step, (*Debugger).Step, (*Debugger).Step,
+				(*Debugger).Step,
+			},
+			expected: []string{
+				"entry 2 (* (group (+ 1 2)) (- 3))",
+				"step 1 (group (+ 1 2))",
+				"step 1 (+ 1 2)",
+				"step 1 1",
+				"step 2 2",
+				"step 3 (- 3)",
+				"step 3 3",
+			},
+		},
+		{
+			name: "next skips nested expressions",
+			commands: []func(*Debugger){
+				(*Debugger).Step, (*Debugger).Next, (*Debugger).Next,
+			},
+			expected: []string{
+				"entry 2 (* (group (+ 1 2)) (- 3))",
+				"step 1 (group (+ 1 2))",
+				"step 3 (- 3)",
+			},
+		},
+		{
+			name: "finish leaves the enclosing expression",
+			commands: []func(*Debugger){
+				(*Debugger).Step, (*Debugger).Step, (*Debugger).Step,
+				(*Debugger).Finish, (*Debugger).Continue,
+			},
+			expected: []string{
+				"entry 2 (* (group (+ 1 2)) (- 3))",
+				"step 1 (group (+ 1 2))",
+				"step 1 (+ 1 2)",
+				"step 1 1",
+				"step 3 (- 3)",
+			},
+		},
+		{
+			name:        "breakpoint on line",
+			breakpoints: []int{3},
+			commands:    []func(*Debugger){(*Debugger).Continue, (*Debugger).Continue},
+			expected: []string{
+				"entry 2 (* (group (+ 1 2)) (- 3))",
+				"breakpoint 3 (- 3)",
+			},
+		},
+		{
+			name:        "breakpoint stops once per line entered",
+			breakpoints: []int{1},
+			commands:    []func(*Debugger){(*Debugger).Continue, (*Debugger).Continue},
+			expected: []string{
+				"entry 2 (* (group (+ 1 2)) (- 3))",
+				"breakpoint 1 (group (+ 1 2))",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			asrt := assert.New(t)
+			var stops []string
+			var debugger *Debugger
+			debugger = NewDebugger(func(reason StopReason, frames []Frame) {
+				current := frames[len(frames)-1]
+				stops = append(stops, fmt.Sprintf("%s %d %s", reason, current.Line, printExpr(current.Expr)))
+				if len(stops) <= len(tt.commands) {
+					tt.commands[len(stops)-1](debugger)
+				} else {
+					debugger.Continue()
+				}
+			})
+			for _, line := range tt.breakpoints {
+				debugger.SetBreakpoint(line)
+			}
+
+			interp := NewInterpreter()
+			interp.SetHook(debugger)
+			result, err := interp.Interpret(parseSource(t, source))
+
+			asrt.NoError(err)
+			asrt.Equal(-9.0, result)
+			asrt.Equal(tt.expected, stops)
+		})
+	}
+}
+
+func TestInterpreter_Frames(t *testing.T) {
+	asrt := assert.New(t)
+	var backtraces [][]int
+	hook := hookFunc(func(frames []Frame) {
+		lines := []int{}
+		for _, frame := range frames {
+			lines = append(lines, frame.Line)
+		}
+		backtraces = append(backtraces, lines)
+	})
+
+	interp := NewInterpreter()
+	interp.SetHook(hook)
+	_, err := interp.Interpret(parseSource(t, "1 +\n-2"))
+
+	asrt.NoError(err)
+	asrt.Equal([][]int{{1}, {1, 1}, {1, 2}, {1, 2, 2}}, backtraces)
+	asrt.Empty(interp.Frames())
+}
+
+type hookFunc func(frames []Frame)
+
+func (h hookFunc) BeforeExpr(frames []Frame) {
+	h(frames)
+}
+
+func TestBreakableLines(t *testing.T) {
+	tests := []struct {
+		name     string
+		source   string
+		expected map[int]bool
+	}{
+		{name: "expressions", source: "print 1 +\n2;", expected: map[int]bool{1: true, 2: true}},
+		{name: "var without initializer", source: "var x;\nx = 1;", expected: map[int]bool{2: true}},
+		{name: "braces", source: "{\nprint 1;\n}", expected: map[int]bool{2: true}},
+		{name: "operator line", source: "1\n+\n2;", expected: map[int]bool{1: true, 2: true, 3: true}},
+		{name: "empty", source: "", expected: map[int]bool{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, BreakableLines(parseProgram(t, tt.source)))
+		})
+	}
+}
@@ -0,0 +1,68 @@
+package lox
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// instruction is one node of a compiled program, as Disassemble lists it.
+type instruction struct {
+	offset   int
+	depth    int
+	node     Node
+	operands []string
+}
+
+// Disassemble writes a listing of statements as WriteCompiled would encode
+// them: the constant pool, then each node of the code with its offset into
+// the code, its source line and its operands, indented to show nesting. A
+// line of "|" means the node is on the same line as the one before it, and a
+// missing optional node, such as an absent else branch, is listed as None.
+// Tokens are listed as the pool index of their lexeme, then the lexeme.
+func Disassemble(w io.Writer, statements []Stmt) error {
+	var listing []*instruction
+	e := &compiledEncoder{constants: map[constantKey]int{}, listing: &listing}
+	e.stmts(statements)
+
+	var out strings.Builder
+	out.WriteString("== constants ==\n")
+	for idx, c := range e.pool {
+		if s, ok := c.(string); ok {
+			fmt.Fprintf(&out, "%04d %q\n", idx, s)
+		} else {
+			fmt.Fprintf(&out, "%04d %s\n", idx, Stringify(c))
+		}
+	}
+
+	out.WriteString("== code ==\n")
+	previous := -1
+	for _, ins := range listing {
+		line := "   |"
+		if ins.node != nil && instructionLine(ins.node) != previous {
+			previous = instructionLine(ins.node)
+			line = fmt.Sprintf("%4d", previous)
+		}
+		fmt.Fprintf(&out, "%04d %s %s%s", ins.offset, line, strings.Repeat("  ", ins.depth), nodeName(ins.node))
+		for _, operand := range ins.operands {
+			fmt.Fprintf(&out, " %s", operand)
+		}
+		out.WriteString("\n")
+	}
+
+	_, err := io.WriteString(w, out.String())
+	return err
+}
+
+// nodeName is the name of n's type, or None for a missing node.
+func nodeName(n Node) string {
+	if n == nil {
+		return "None"
+	}
+	return strings.TrimPrefix(fmt.Sprintf("%T", n), "lox.")
+}
+
+// instructionLine is the line an error or the debugger would report for n.
+func instructionLine(n Node) int {
+	return OriginOf(n).Line
+}
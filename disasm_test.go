@@ -0,0 +1,92 @@
+// ABOUTME: Tests for the disassembly listing of compiled programs
+// ABOUTME: Compares listings against golden output and checks they survive a round trip
+package lox
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func disassemble(t *testing.T, statements []Stmt) string {
+	t.Helper()
+	var out strings.Builder
+	assert.NoError(t, Disassemble(&out, statements))
+	return out.String()
+}
+
+func TestDisassemble(t *testing.T) {
+	tests := []struct {
+		name     string
+		source   string
+		expected string
+	}{
+		{
+			name:     "empty program",
+			source:   "",
+			expected: "== constants ==\n== code ==\n",
+		},
+		{
+			name:   "nested expressions",
+			source: "var x = 1;\nprint x + \"a\";",
+			expected: `== constants ==
+0000 "var"
+0001 "x"
+0002 1
+0003 "1"
+0004 "print"
+0005 "+"
+0006 "a"
+0007 "\"a\""
+== code ==
+0001    1 VarStmt #0 'var' #1 'x'
+0012    |   Literal #2 1 #3 '1'
+0022    2 PrintStmt #4 'print'
+0028    |   Binary #5 '+'
+0029    |     Variable #1 'x'
+0041    |     Literal #6 "a" #7 '"a"'
+`,
+		},
+		{
+			name:   "missing nodes",
+			source: "var y;\nif (y)\n  print true;",
+			expected: `== constants ==
+0000 "var"
+0001 "y"
+0002 "if"
+0003 "print"
+0004 "true"
+== code ==
+0001    1 VarStmt #0 'var' #1 'y'
+0012    |   None
+0014    2 IfStmt #2 'if'
+0020    |   Variable #1 'y'
+0027    3   PrintStmt #3 'print'
+0033    |     Literal true #4 'true'
+0042    |   None
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, disassemble(t, parseProgram(t, tt.source)))
+		})
+	}
+}
+
+func TestDisassemble_FoldedOrigin(t *testing.T) {
+	listing := disassemble(t, Optimize(parseProgram(t, "print (1 +\n2);")))
+	assert.Contains(t, listing, "|   Literal #1 3 #2 '3' from lines 1-2\n")
+}
+
+func TestDisassemble_CompiledProgram(t *testing.T) {
+	asrt := assert.New(t)
+	statements := Optimize(parseProgram(t, "var xs = [1, 2];\nfor x in xs { print -x; }"))
+
+	decoded, err := ReadCompiled(bytes.NewReader(compile(t, statements)))
+	asrt.NoError(err)
+	asrt.Equal(disassemble(t, statements), disassemble(t, decoded))
+}
@@ -0,0 +1,155 @@
+package lox
+
+import (
+	"fmt"
+	"slices"
+)
+
+// Environment holds variables in numbered slots, in the order they are
+// defined. The resolver numbers the variables of each scope the same way, so
+// resolved code reaches them by index without comparing names. Lookups by
+// name that miss fall through to the enclosing environment.
+type Environment struct {
+	names     []string
+	values    []Value
+	index     map[string]int
+	enclosing *Environment
+}
+
+// undefinedSlot marks a slot that has been numbered but not yet defined,
+// such as a global the resolver has seen used before its declaration.
+type undefinedSlot struct{}
+
+var undefined Value = &undefinedSlot{}
+
+func NewEnvironment(enclosing *Environment) *Environment {
+	return &Environment{enclosing: enclosing}
+}
+
+// newTable returns an environment that also indexes its slots by name. It
+// is used for the globals and built-ins, which hold too many variables to
+// search one by one.
+func newTable(enclosing *Environment) *Environment {
+	return &Environment{index: map[string]int{}, enclosing: enclosing}
+}
+
+func (e *Environment) Define(name string, value Value) {
+	slot, ok := e.slotOf(name)
+	if !ok {
+		slot = len(e.values)
+	}
+	e.defineAt(slot, name, value)
+}
+
+// defineAt defines name in the given slot, numbering any slots before it
+// that don't exist yet.
+func (e *Environment) defineAt(slot int, name string, value Value) {
+	for len(e.values) <= slot {
+		e.names = append(e.names, "")
+		e.values = append(e.values, undefined)
+	}
+	e.names[slot], e.values[slot] = name, value
+	if e.index != nil {
+		e.index[name] = slot
+	}
+}
+
+// reserve returns the slot for name, numbering a new, undefined slot if
+// there isn't one yet.
+func (e *Environment) reserve(name string) int {
+	if slot, ok := e.slotOf(name); ok {
+		return slot
+	}
+	slot := len(e.values)
+	e.defineAt(slot, name, undefined)
+	return slot
+}
+
+// slotOf finds the slot numbered for name in this environment, whether or
+// not it has been defined.
+func (e *Environment) slotOf(name string) (int, bool) {
+	if e.index != nil {
+		slot, ok := e.index[name]
+		return slot, ok
+	}
+	for slot := len(e.names) - 1; slot >= 0; slot-- {
+		if e.names[slot] == name {
+			return slot, true
+		}
+	}
+	return 0, false
+}
+
+// Enclosing returns the environment this one is nested in, or nil for the
+// outermost one.
+func (e *Environment) Enclosing() *Environment {
+	return e.enclosing
+}
+
+// ancestor returns the environment depth levels out from this one.
+func (e *Environment) ancestor(depth int) *Environment {
+	env := e
+	for range depth {
+		env = env.enclosing
+	}
+	return env
+}
+
+// getAt returns the value in slot, if it has been defined.
+func (e *Environment) getAt(slot int) (Value, bool) {
+	if slot >= len(e.values) || e.values[slot] == undefined {
+		return nil, false
+	}
+	return e.values[slot], true
+}
+
+// assignAt updates the value in slot, if it has been defined.
+func (e *Environment) assignAt(slot int, value Value) bool {
+	if slot >= len(e.values) || e.values[slot] == undefined {
+		return false
+	}
+	e.values[slot] = value
+	return true
+}
+
+func (e *Environment) Get(name Token) (Value, error) {
+	if value, ok := e.Lookup(name.Lexeme); ok {
+		return value, nil
+	}
+	return nil, fmt.Errorf("undefined variable '%s'", name.Lexeme)
+}
+
+// Assign updates an existing variable in the nearest environment that
+// defines it.
+func (e *Environment) Assign(name Token, value Value) error {
+	for env := e; env != nil; env = env.enclosing {
+		if slot, ok := env.slotOf(name.Lexeme); ok && env.assignAt(slot, value) {
+			return nil
+		}
+	}
+	return fmt.Errorf("undefined variable '%s'", name.Lexeme)
+}
+
+// Lookup finds name in this environment or any enclosing one.
+func (e *Environment) Lookup(name string) (Value, bool) {
+	for env := e; env != nil; env = env.enclosing {
+		if slot, ok := env.slotOf(name); ok {
+			if value, ok := env.getAt(slot); ok {
+				return value, true
+			}
+		}
+	}
+	return nil, false
+}
+
+// Names lists the variables defined directly in this environment, sorted.
+func (e *Environment) Names() []string {
+	var names []string
+	for slot, name := range e.names {
+		if e.values[slot] != undefined {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+	return slices.Compact(names)
+}
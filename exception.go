@@ -0,0 +1,91 @@
+package lox
+
+import (
+	"fmt"
+)
+
+// ErrorValue is the value a catch clause receives when a built-in runtime
+// error, such as a type error or an undefined variable, is thrown. Lox code
+// reads its message and line properties.
+type ErrorValue struct {
+	message string
+	line    int
+}
+
+func (e *ErrorValue) Message() string {
+	return e.message
+}
+
+func (e *ErrorValue) Line() int {
+	return e.line
+}
+
+func (e *ErrorValue) String() string {
+	return fmt.Sprintf("<error: %s>", e.message)
+}
+
+func (e *ErrorValue) property(name string) (Value, bool) {
+	switch name {
+	case "message":
+		return e.message, true
+	case "line":
+		return float64(e.line), true
+	}
+	return nil, false
+}
+
+// thrown is the error that carries a thrown value, from a throw statement or
+// a runtime error, up to the nearest enclosing try statement. If there is
+// none, the program fails with err.
+type thrown struct {
+	value Value
+	err   *Error
+}
+
+func (t *thrown) Error() string {
+	return t.err.Error()
+}
+
+func (t *thrown) Unwrap() error {
+	return t.err
+}
+
+// throw returns an error that throws value. err describes value for the case
+// where nothing catches it.
+func (i *Interpreter) throw(value Value, err *Error) error {
+	err.Trace = i.trace(err.Line)
+	return &thrown{value: value, err: err}
+}
+
+// uncaught builds the error reported for a value thrown by a throw statement
+// that nothing catches.
+func uncaught(value Value, line int) *Error {
+	if e, ok := value.(*ErrorValue); ok {
+		return &Error{Line: e.line, Message: e.message, kind: ErrLoxRuntime}
+	}
+	return &Error{Line: line, Message: "uncaught exception: " + quote(value), kind: ErrLoxRuntime}
+}
+
+// trace lists the calls in progress, innermost first, for an error on line.
+func (i *Interpreter) trace(line int) []TraceEntry {
+	trace := []TraceEntry{}
+	for depth := len(i.frames) - 1; depth >= 0; depth-- {
+		call, ok := i.frames[depth].Expr.(Call)
+		if !ok {
+			continue
+		}
+		trace = append(trace, TraceEntry{Function: calleeName(call.callee), Line: line})
+		line = call.paren.Line
+	}
+	return append(trace, TraceEntry{Function: "<script>", Line: line})
+}
+
+func calleeName(callee Expr) string {
+	switch callee := callee.(type) {
+	case Variable:
+		return callee.name.Lexeme
+	case Get:
+		return callee.name.Lexeme
+	}
+	return printExpr(callee)
+}
@@ -0,0 +1,136 @@
+// ABOUTME: Tests for throw and try/catch/finally statements
+// ABOUTME: Covers catching thrown values and runtime errors, finally ordering and stack traces
+package lox
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestException(t *testing.T) {
+	tests := []struct {
+		name     string
+		source   string
+		output   string
+		errorMsg string
+		trace    string
+	}{
+		{
+			name:   "catch thrown value",
+			source: `try { throw "boom"; print "unreachable"; } catch (e) { print e; }`,
+			output: "boom\n",
+		},
+		{
+			name:   "catch runtime error",
+			source: "try {\n  print 1 + nil;\n} catch (e) {\n  print e.message;\n  print e.line;\n  print type(e);\n}",
+			output: "operands to + must both be numbers or strings\n2\nerror\n",
+		},
+		{
+			name:   "catch undefined variable",
+			source: `try { missing; } catch (e) { print e.message; }`,
+			output: "undefined variable 'missing'\n",
+		},
+		{
+			name:   "catch native error",
+			source: `try { sqrt("4"); } catch (e) { print e.message; }`,
+			output: "sqrt() expects a number but got string\n",
+		},
+		{
+			name:   "catch error from callback",
+			source: `try { [1].map(pow); } catch (e) { print e.message; }`,
+			output: "expected 2 arguments but got 1\n",
+		},
+		{
+			name:   "finally runs after success",
+			source: `try { print "body"; } finally { print "finally"; }`,
+			output: "body\nfinally\n",
+		},
+		{
+			name:   "finally runs after catch",
+			source: `try { throw 1; } catch (e) { print "catch"; } finally { print "finally"; }`,
+			output: "catch\nfinally\n",
+		},
+		{
+			name:   "nested try rethrows",
+			source: `try { try { throw "inner"; } catch (e) { throw e + "!"; } } catch (e) { print e; }`,
+			output: "inner!\n",
+		},
+		{
+			name:   "finally runs before propagating",
+			source: `try { try { throw "x"; } finally { print "cleanup"; } } catch (e) { print "caught " + e; }`,
+			output: "cleanup\ncaught x\n",
+		},
+		{
+			name:   "catch variable is scoped to the catch body",
+			source: `var e = "outer"; try { throw "inner"; } catch (e) { } print e;`,
+			output: "outer\n",
+		},
+		{
+			name:   "execution continues after try",
+			source: `try { throw nil; } catch (e) { print e; } print "after";`,
+			output: "nil\nafter\n",
+		},
+		{
+			name:     "uncaught throw",
+			source:   "print \"before\";\nthrow \"boom\";\nprint \"after\";",
+			output:   "before\n",
+			errorMsg: "[line 2] runtime error: uncaught exception: \"boom\"",
+			trace:    "    at <script> (line 2)\n",
+		},
+		{
+			name:     "uncaught throw through finally",
+			source:   `try { throw 42; } finally { print "finally"; }`,
+			output:   "finally\n",
+			errorMsg: "[line 1] runtime error: uncaught exception: 42",
+			trace:    "    at <script> (line 1)\n",
+		},
+		{
+			name:     "rethrown runtime error keeps its message and line",
+			source:   "try {\n  -\"a\";\n} catch (e) {\n  throw e;\n}",
+			errorMsg: "[line 2] runtime error: operand to - must be a number",
+			trace:    "    at <script> (line 2)\n",
+		},
+		{
+			name:     "native error trace",
+			source:   "try {\n  floor(\n    nil);\n} finally {}",
+			errorMsg: "[line 3] runtime error: floor() expects a number but got nil",
+			trace:    "    at floor (line 3)\n    at <script> (line 3)\n",
+		},
+		{
+			name:     "callback error trace",
+			source:   "try {\n  [1].map(\n    pow);\n} finally {}",
+			errorMsg: "[line 3] runtime error: expected 2 arguments but got 1",
+			trace:    "    at map (line 3)\n    at <script> (line 3)\n",
+		},
+		{
+			name:     "unknown error property",
+			source:   `try { nil + 1; } catch (e) { e.code; }`,
+			errorMsg: "[line 1] runtime error: undefined property 'code'",
+			trace:    "    at <script> (line 1)\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			asrt := assert.New(t)
+			var out bytes.Buffer
+			_, err := NewVM(WithStdout(&out)).Eval(tt.source)
+
+			asrt.Equal(tt.output, out.String())
+			if tt.errorMsg != "" {
+				asrt.ErrorIs(err, ErrLoxRuntime)
+				asrt.EqualError(err, tt.errorMsg)
+				asrt.Equal(tt.trace, StackTrace(err))
+				return
+			}
+			asrt.NoError(err)
+		})
+	}
+}
+
+func TestException_LimitsAreNotCatchable(t *testing.T) {
+	_, err := NewVM(WithMemoryLimit(10)).Eval(`try { "aaaaaaaa" + "aaaaaaaa"; } catch (e) { print "caught"; }`)
+	assert.ErrorIs(t, err, ErrMemoryLimitExceeded)
+}
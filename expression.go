@@ -1,46 +1,491 @@
 package lox
 
+import (
+	"fmt"
+	"slices"
+)
+
+// ExprVisitor is implemented by code that walks expression trees and computes
+// a value of type R for each node. Use Accept to dispatch to it.
+type ExprVisitor[R any] interface {
+	VisitBinary(b Binary) (R, error)
+	VisitUnary(u Unary) (R, error)
+	VisitGroup(g Group) (R, error)
+	VisitLiteral(l Literal) (R, error)
+	VisitVariable(v Variable) (R, error)
+	VisitCall(c Call) (R, error)
+	VisitAssign(a Assign) (R, error)
+	VisitList(l ListLiteral) (R, error)
+	VisitMap(m MapLiteral) (R, error)
+	VisitIndex(i Index) (R, error)
+	VisitSetIndex(s SetIndex) (R, error)
+	VisitGet(g Get) (R, error)
+}
+
+// Accept calls the method of v that matches e's node type.
+func Accept[R any](e Expr, v ExprVisitor[R]) (R, error) {
+	switch e := e.(type) {
+	case Binary:
+		return v.VisitBinary(e)
+	case Unary:
+		return v.VisitUnary(e)
+	case Group:
+		return v.VisitGroup(e)
+	case Literal:
+		return v.VisitLiteral(e)
+	case Variable:
+		return v.VisitVariable(e)
+	case Call:
+		return v.VisitCall(e)
+	case Assign:
+		return v.VisitAssign(e)
+	case ListLiteral:
+		return v.VisitList(e)
+	case MapLiteral:
+		return v.VisitMap(e)
+	case Index:
+		return v.VisitIndex(e)
+	case SetIndex:
+		return v.VisitSetIndex(e)
+	case Get:
+		return v.VisitGet(e)
+	}
+	var zero R
+	return zero, fmt.Errorf("unknown expression type %T", e)
+}
+
+// Visitor is the original expression visitor, which returns nothing and leaves
+// visitors to keep their results in their own state. It is still supported
+// through Expr.Accept, but new code should implement ExprVisitor instead.
 type Visitor interface {
 	VisitBinary(b Binary)
 	VisitUnary(u Unary)
 	VisitGroup(g Group)
 	VisitLiteral(l Literal)
+	VisitVariable(v Variable)
+	VisitCall(c Call)
+	VisitAssign(a Assign)
+	VisitList(l ListLiteral)
+	VisitMap(m MapLiteral)
+	VisitIndex(i Index)
+	VisitSetIndex(s SetIndex)
+	VisitGet(g Get)
 }
 
 type Expr interface {
+	Node
+	// Accept calls the method of a Visitor that matches the node type. To
+	// use an ExprVisitor, call the package-level Accept function instead.
 	Accept(v Visitor)
 }
 
+// Binary is an infix operation such as a + b.
 type Binary struct {
 	left, right Expr
 	operator    Token
+	origin
+}
+
+func NewBinary(left Expr, operator Token, right Expr) Binary {
+	return Binary{left: left, operator: operator, right: right}
+}
+
+func (b Binary) Left() Expr {
+	return b.left
+}
+
+func (b Binary) Operator() Token {
+	return b.operator
+}
+
+func (b Binary) Right() Expr {
+	return b.right
+}
+
+func (b Binary) Pos() Position {
+	return b.startOr(b.left.Pos())
+}
+
+func (b Binary) End() Position {
+	return b.endOr(b.right.End())
 }
 
 func (b Binary) Accept(v Visitor) {
 	v.VisitBinary(b)
 }
 
+// Unary is a prefix operation such as -a or !a.
 type Unary struct {
 	right    Expr
 	operator Token
+	origin
+}
+
+func NewUnary(operator Token, right Expr) Unary {
+	return Unary{operator: operator, right: right}
+}
+
+func (u Unary) Operator() Token {
+	return u.operator
+}
+
+func (u Unary) Right() Expr {
+	return u.right
+}
+
+func (u Unary) Pos() Position {
+	return u.startOr(tokenPos(u.operator))
+}
+
+func (u Unary) End() Position {
+	return u.endOr(u.right.End())
 }
 
 func (u Unary) Accept(v Visitor) {
 	v.VisitUnary(u)
 }
 
+// Group is a parenthesized expression. The parentheses aren't recorded, so
+// its span is that of the expression inside.
 type Group struct {
 	expr Expr
+	origin
+}
+
+func NewGroup(expr Expr) Group {
+	return Group{expr: expr}
+}
+
+func (g Group) Expression() Expr {
+	return g.expr
+}
+
+func (g Group) Pos() Position {
+	return g.startOr(g.expr.Pos())
+}
+
+func (g Group) End() Position {
+	return g.endOr(g.expr.End())
 }
 
 func (g Group) Accept(v Visitor) {
 	v.VisitGroup(g)
 }
 
+// Literal is a number, string, boolean or nil written in the source.
 type Literal struct {
 	literal any
+	token   Token
+	origin
+}
+
+func NewLiteral(value any, token Token) Literal {
+	return Literal{literal: value, token: token}
+}
+
+func (l Literal) Value() any {
+	return l.literal
+}
+
+func (l Literal) Token() Token {
+	return l.token
+}
+
+func (l Literal) Pos() Position {
+	return l.startOr(tokenPos(l.token))
+}
+
+func (l Literal) End() Position {
+	return l.endOr(tokenEnd(l.token))
 }
 
 func (l Literal) Accept(v Visitor) {
 	v.VisitLiteral(l)
 }
+
+type Variable struct {
+	name    Token
+	binding *binding
+	origin
+}
+
+func NewVariable(name Token) Variable {
+	return Variable{name: name}
+}
+
+func (va Variable) Name() Token {
+	return va.name
+}
+
+func (va Variable) Pos() Position {
+	return va.startOr(tokenPos(va.name))
+}
+
+func (va Variable) End() Position {
+	return va.endOr(tokenEnd(va.name))
+}
+
+func (va Variable) Accept(v Visitor) {
+	v.VisitVariable(va)
+}
+
+// Call is a function call. paren is the closing parenthesis, where errors
+// from the call are reported.
+type Call struct {
+	callee    Expr
+	paren     Token
+	arguments []Expr
+	origin
+}
+
+func NewCall(callee Expr, paren Token, arguments ...Expr) Call {
+	return Call{callee: callee, paren: paren, arguments: arguments}
+}
+
+func (c Call) Callee() Expr {
+	return c.callee
+}
+
+func (c Call) Paren() Token {
+	return c.paren
+}
+
+func (c Call) Arguments() []Expr {
+	return slices.Clone(c.arguments)
+}
+
+func (c Call) Pos() Position {
+	return c.startOr(c.callee.Pos())
+}
+
+func (c Call) End() Position {
+	return c.endOr(tokenEnd(c.paren))
+}
+
+func (c Call) Accept(v Visitor) {
+	v.VisitCall(c)
+}
+
+type Assign struct {
+	name    Token
+	value   Expr
+	binding *binding
+	origin
+}
+
+func NewAssign(name Token, value Expr) Assign {
+	return Assign{name: name, value: value}
+}
+
+func (a Assign) Name() Token {
+	return a.name
+}
+
+func (a Assign) Value() Expr {
+	return a.value
+}
+
+func (a Assign) Pos() Position {
+	return a.startOr(tokenPos(a.name))
+}
+
+func (a Assign) End() Position {
+	return a.endOr(a.value.End())
+}
+
+func (a Assign) Accept(v Visitor) {
+	v.VisitAssign(a)
+}
+
+// ListLiteral is a list written as [a, b, c]. bracket and closing are the
+// opening and closing brackets.
+type ListLiteral struct {
+	bracket  Token
+	elements []Expr
+	closing  Token
+	origin
+}
+
+func NewListLiteral(bracket Token, elements []Expr, closing Token) ListLiteral {
+	return ListLiteral{bracket: bracket, elements: elements, closing: closing}
+}
+
+func (l ListLiteral) Bracket() Token {
+	return l.bracket
+}
+
+func (l ListLiteral) Elements() []Expr {
+	return slices.Clone(l.elements)
+}
+
+func (l ListLiteral) Closing() Token {
+	return l.closing
+}
+
+func (l ListLiteral) Pos() Position {
+	return l.startOr(tokenPos(l.bracket))
+}
+
+func (l ListLiteral) End() Position {
+	return l.endOr(tokenEnd(l.closing))
+}
+
+func (l ListLiteral) Accept(v Visitor) {
+	v.VisitList(l)
+}
+
+// MapLiteral is a map written as {k: v}. keys and values are parallel: the
+// entry at each index is keys[i]: values[i].
+type MapLiteral struct {
+	brace   Token
+	keys    []Expr
+	values  []Expr
+	closing Token
+	origin
+}
+
+// NewMapLiteral builds a map literal. It panics if keys and values have
+// different lengths.
+func NewMapLiteral(brace Token, keys, values []Expr, closing Token) MapLiteral {
+	if len(keys) != len(values) {
+		panic(fmt.Sprintf("lox: map literal with %d keys and %d values", len(keys), len(values)))
+	}
+	return MapLiteral{brace: brace, keys: keys, values: values, closing: closing}
+}
+
+func (m MapLiteral) Brace() Token {
+	return m.brace
+}
+
+func (m MapLiteral) Keys() []Expr {
+	return slices.Clone(m.keys)
+}
+
+func (m MapLiteral) Values() []Expr {
+	return slices.Clone(m.values)
+}
+
+func (m MapLiteral) Closing() Token {
+	return m.closing
+}
+
+func (m MapLiteral) Pos() Position {
+	return m.startOr(tokenPos(m.brace))
+}
+
+func (m MapLiteral) End() Position {
+	return m.endOr(tokenEnd(m.closing))
+}
+
+func (m MapLiteral) Accept(v Visitor) {
+	v.VisitMap(m)
+}
+
+// Index reads an element of a list or map, as in object[index]. bracket is
+// the opening bracket.
+type Index struct {
+	object  Expr
+	bracket Token
+	index   Expr
+	origin
+}
+
+func NewIndex(object Expr, bracket Token, index Expr) Index {
+	return Index{object: object, bracket: bracket, index: index}
+}
+
+func (i Index) Object() Expr {
+	return i.object
+}
+
+func (i Index) Bracket() Token {
+	return i.bracket
+}
+
+func (i Index) Index() Expr {
+	return i.index
+}
+
+func (i Index) Pos() Position {
+	return i.startOr(i.object.Pos())
+}
+
+func (i Index) End() Position {
+	return i.endOr(i.index.End())
+}
+
+func (i Index) Accept(v Visitor) {
+	v.VisitIndex(i)
+}
+
+// SetIndex assigns to an element of a list or map, as in object[index] = value.
+type SetIndex struct {
+	object  Expr
+	bracket Token
+	index   Expr
+	value   Expr
+	origin
+}
+
+func NewSetIndex(object Expr, bracket Token, index, value Expr) SetIndex {
+	return SetIndex{object: object, bracket: bracket, index: index, value: value}
+}
+
+func (s SetIndex) Object() Expr {
+	return s.object
+}
+
+func (s SetIndex) Bracket() Token {
+	return s.bracket
+}
+
+func (s SetIndex) Index() Expr {
+	return s.index
+}
+
+func (s SetIndex) Value() Expr {
+	return s.value
+}
+
+func (s SetIndex) Pos() Position {
+	return s.startOr(s.object.Pos())
+}
+
+func (s SetIndex) End() Position {
+	return s.endOr(s.value.End())
+}
+
+func (s SetIndex) Accept(v Visitor) {
+	v.VisitSetIndex(s)
+}
+
+// Get reads a property, such as a list method, as in object.name.
+type Get struct {
+	object Expr
+	name   Token
+	origin
+}
+
+func NewGet(object Expr, name Token) Get {
+	return Get{object: object, name: name}
+}
+
+func (g Get) Object() Expr {
+	return g.object
+}
+
+func (g Get) Name() Token {
+	return g.name
+}
+
+func (g Get) Pos() Position {
+	return g.startOr(g.object.Pos())
+}
+
+func (g Get) End() Position {
+	return g.endOr(tokenEnd(g.name))
+}
+
+func (g Get) Accept(v Visitor) {
+	v.VisitGet(g)
+}
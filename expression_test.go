@@ -0,0 +1,110 @@
+// ABOUTME: Tests for dispatching expressions to generic and legacy visitors
+// ABOUTME: Uses a node-counting ExprVisitor, the result-less Visitor and AstPrinter through both
+package lox
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// depthVisitor computes the depth of an expression tree.
+type depthVisitor struct{}
+
+func (d depthVisitor) deepest(exprs ...Expr) (int, error) {
+	depth := 0
+	for _, expr := range exprs {
+		n, err := Accept[int](expr, d)
+		if err != nil {
+			return 0, err
+		}
+		depth = max(depth, n)
+	}
+	return depth + 1, nil
+}
+
+func (d depthVisitor) VisitBinary(b Binary) (int, error)   { return d.deepest(b.left, b.right) }
+func (d depthVisitor) VisitUnary(u Unary) (int, error)     { return d.deepest(u.right) }
+func (d depthVisitor) VisitGroup(g Group) (int, error)     { return d.deepest(g.expr) }
+func (d depthVisitor) VisitLiteral(Literal) (int, error)   { return 1, nil }
+func (d depthVisitor) VisitVariable(Variable) (int, error) { return 1, nil }
+func (d depthVisitor) VisitCall(c Call) (int, error) {
+	return d.deepest(append(c.arguments, c.callee)...)
+}
+func (d depthVisitor) VisitAssign(a Assign) (int, error)    { return d.deepest(a.value) }
+func (d depthVisitor) VisitList(l ListLiteral) (int, error) { return d.deepest(l.elements...) }
+func (d depthVisitor) VisitMap(m MapLiteral) (int, error) {
+	return d.deepest(append(m.keys, m.values...)...)
+}
+func (d depthVisitor) VisitIndex(i Index) (int, error) { return d.deepest(i.object, i.index) }
+func (d depthVisitor) VisitSetIndex(s SetIndex) (int, error) {
+	return d.deepest(s.object, s.index, s.value)
+}
+func (d depthVisitor) VisitGet(g Get) (int, error) { return d.deepest(g.object) }
+
+// unknownExpr is an expression type the package doesn't know about.
+type unknownExpr struct{}
+
+func (unknownExpr) Accept(Visitor) {}
+func (unknownExpr) Pos() Position  { return Position{} }
+func (unknownExpr) End() Position  { return Position{} }
+
+func TestAccept(t *testing.T) {
+	tests := []struct {
+		name     string
+		source   string
+		expected int
+	}{
+		{name: "literal", source: "1", expected: 1},
+		{name: "binary", source: "1 + 2", expected: 2},
+		{name: "nested", source: "-(1 + 2)", expected: 4},
+		{name: "call", source: "f(1, [2, -3])", expected: 4},
+		{name: "map and index", source: `{"a": [1]}["a"]`, expected: 4},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			asrt := assert.New(t)
+			depth, err := Accept[int](parseSource(t, tt.source), depthVisitor{})
+			asrt.NoError(err)
+			asrt.Equal(tt.expected, depth)
+		})
+	}
+}
+
+func TestAccept_UnknownExpression(t *testing.T) {
+	_, err := Accept[int](Unary{right: unknownExpr{}}, depthVisitor{})
+	assert.EqualError(t, err, "unknown expression type lox.unknownExpr")
+}
+
+func TestVisitor_Legacy(t *testing.T) {
+	tests := []struct {
+		source   string
+		expected any
+	}{
+		{source: "42", expected: 42.0},
+		{source: "1 + 2", expected: "binary expression"},
+		{source: "xs[0] = 1", expected: "set index expression"},
+		{source: "xs.len", expected: "get expression"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.source, func(t *testing.T) {
+			visitor := &testVisitor{}
+			parseSource(t, tt.source).Accept(visitor)
+			assert.Equal(t, tt.expected, visitor.result)
+		})
+	}
+}
+
+func TestAstPrinter_Legacy(t *testing.T) {
+	var _ Visitor = &AstPrinter{}
+	for _, source := range []string{"42", "-(1 + 2)", `xs[0] = {"a": f(1)}`, "xs.len"} {
+		t.Run(source, func(t *testing.T) {
+			expr := parseSource(t, source)
+			printer := &AstPrinter{}
+			expr.Accept(printer)
+			assert.Equal(t, printer.Print(expr), printer.Result())
+		})
+	}
+}
@@ -0,0 +1,62 @@
+package lox
+
+import "strings"
+
+// stringTable interns strings, so that equal strings added to it share the
+// same bytes. Comparing two such strings, as map keys and property names
+// do, finishes as soon as their pointers match instead of comparing every
+// byte.
+//
+// Tables are never shared or global. Each Scanner interns the names and
+// string literals it scans in its own table, which lives as long as the
+// tokens do, and each evaluation by an Interpreter interns the string
+// constants of the program it runs, so that concatenations producing one
+// of them can reuse it. Neither holds anything the program doesn't.
+type stringTable struct {
+	entries map[string]string
+}
+
+func newStringTable() *stringTable {
+	return &stringTable{entries: map[string]string{}}
+}
+
+// intern returns the interned copy of s, adding a copy of s to the table if
+// it is not there yet. Copying means that interning part of a larger string,
+// such as a name sliced from the source, doesn't keep the rest of it alive.
+func (t *stringTable) intern(s string) string {
+	if interned, ok := t.entries[s]; ok {
+		return interned
+	}
+	s = strings.Clone(s)
+	t.entries[s] = s
+	return s
+}
+
+// lookup returns the interned string equal to s, if there is one. A nil
+// table holds nothing.
+func (t *stringTable) lookup(s string) (string, bool) {
+	if t == nil {
+		return "", false
+	}
+	interned, ok := t.entries[s]
+	return interned, ok
+}
+
+// len returns the number of strings in the table.
+func (t *stringTable) len() int {
+	return len(t.entries)
+}
+
+// concat returns l+r. If the result is one of the program's interned string
+// constants, that copy is returned, so that the new string can be collected
+// straight away. Other results are not added to the table, so that programs
+// building many strings don't fill it. VisitBinary charges the result
+// against the memory limit either way, so that limits don't depend on what
+// has been interned.
+func (i *Interpreter) concat(l, r string) string {
+	s := l + r
+	if interned, ok := i.strings.lookup(s); ok {
+		return interned
+	}
+	return s
+}
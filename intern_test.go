@@ -0,0 +1,78 @@
+// ABOUTME: Tests for interning identifiers and string constants in per-program string tables
+// ABOUTME: Checks equal strings share storage, don't retain the source and that concatenation reuses them
+package lox
+
+import (
+	"bytes"
+	"testing"
+	"unsafe"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func sameStorage(a, b string) bool {
+	return unsafe.StringData(a) == unsafe.StringData(b)
+}
+
+// within reports whether s is stored inside the bytes of source.
+func within(s, source string) bool {
+	start := uintptr(unsafe.Pointer(unsafe.StringData(source)))
+	at := uintptr(unsafe.Pointer(unsafe.StringData(s)))
+	return at >= start && at < start+uintptr(len(source))
+}
+
+func TestIntern_ScannedStrings(t *testing.T) {
+	asrt := assert.New(t)
+	source := `var name = "value"; print name + "value";`
+	tokens, err := NewScanner(source).ScanTokens()
+	asrt.NoError(err)
+	other, err := NewScanner(`name;`).ScanTokens()
+	asrt.NoError(err)
+
+	asrt.True(sameStorage(tokens[1].Lexeme, tokens[6].Lexeme), "identifiers in one program")
+	asrt.False(sameStorage(tokens[1].Lexeme, other[0].Lexeme), "each scanner has its own table")
+	asrt.True(sameStorage(tokens[3].Object.(string), tokens[8].Object.(string)), "string literals")
+	asrt.False(within(tokens[1].Lexeme, source), "identifiers don't keep the source alive")
+	asrt.False(within(tokens[3].Object.(string), source), "string literals don't keep the source alive")
+}
+
+func TestIntern_Concatenation(t *testing.T) {
+	tests := []struct {
+		name     string
+		source   string
+		expected string
+		interned bool
+	}{
+		{name: "known result", source: `"concatenated"; "concat" + "enated";`, expected: "concatenated", interned: true},
+		{name: "empty operand", source: `"" + "alone";`, expected: "alone", interned: true},
+		{name: "constant of an earlier program", source: `"brand" + "new";`, expected: "brandnew", interned: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			asrt := assert.New(t)
+			interp := NewInterpreter()
+			_, err := interp.Execute(parseProgram(t, `"brandnew";`))
+			asrt.NoError(err)
+
+			result, err := interp.Execute(parseProgram(t, tt.source))
+			asrt.NoError(err)
+			asrt.Equal(tt.expected, result)
+			size := interp.strings.len()
+			interned, ok := interp.strings.lookup(tt.expected)
+			asrt.Equal(tt.interned, ok && sameStorage(result.(string), interned))
+			asrt.Equal(size, interp.strings.len(), "results are never added to the table")
+		})
+	}
+}
+
+func TestIntern_CompiledConstants(t *testing.T) {
+	asrt := assert.New(t)
+	statements := parseProgram(t, `print "compiled"; print "compiled";`)
+
+	decoded, err := ReadCompiled(bytes.NewReader(compile(t, statements)))
+	asrt.NoError(err)
+	first := decoded[0].(PrintStmt).Expression().(Literal).Value().(string)
+	second := decoded[1].(PrintStmt).Expression().(Literal).Value().(string)
+	asrt.True(sameStorage(first, second), "constants share their pool entry")
+}
@@ -1,138 +1,589 @@
 package lox
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"io"
+	"math"
+	"slices"
+	"time"
 )
 
 type Interpreter struct {
-	result any
-	errors []error
+	hook        Hook
+	frames      []Frame
+	globals     *Environment
+	environment *Environment
+	stdout      io.Writer
+	stderr      io.Writer
+	stdin       io.Reader
+
+	stepLimit    int
+	maxCallDepth int
+	timeLimit    time.Duration
+	memoryLimit  int
+	limits       limits
+	// running counts the evaluations in progress, which is more than one
+	// when a native function calls back into the interpreter.
+	running int
+
+	// strings holds the string constants of the program being run.
+	strings *stringTable
+
+	tracer *tracer
+}
+
+func NewInterpreter(opts ...Option) *Interpreter {
+	// Built-ins live in their own environment so that globals can shadow
+	// them and listing globals doesn't include them.
+	builtins := newTable(nil)
+	defineStdlib(builtins)
+	globals := newTable(builtins)
+	i := &Interpreter{
+		globals:     globals,
+		environment: globals,
+	}
+	defaultStreams(i)
+	for _, opt := range opts {
+		opt(i)
+	}
+	return i
+}
+
+// Stdout is where program output should be written.
+func (i *Interpreter) Stdout() io.Writer {
+	return i.stdout
+}
+
+// Stderr is where diagnostics should be written.
+func (i *Interpreter) Stderr() io.Writer {
+	return i.stderr
+}
+
+// Stdin is where program input should be read from.
+func (i *Interpreter) Stdin() io.Reader {
+	return i.stdin
 }
 
-func NewInterpreter() *Interpreter {
-	return &Interpreter{}
+// Globals is the outermost environment, shared by every evaluation.
+func (i *Interpreter) Globals() *Environment {
+	return i.globals
+}
+
+// Environment is the innermost environment currently in scope.
+func (i *Interpreter) Environment() *Environment {
+	return i.environment
 }
 
 func (i *Interpreter) Interpret(e Expr) (any, error) {
-	i.result = nil
-	i.errors = []error{}
-	i.evaluate(e)
-	if len(i.errors) > 0 {
-		return i.result, errors.Join(i.errors...)
+	return i.InterpretContext(context.Background(), e)
+}
+
+// InterpretContext evaluates e, stopping early with ErrCanceled if ctx is
+// done or with the matching error if a configured limit is exceeded.
+func (i *Interpreter) InterpretContext(ctx context.Context, e Expr) (any, error) {
+	return i.run(ctx, func() (Value, error) {
+		if i.environment == i.globals {
+			i.strings = newStringTable()
+			e = resolveExpr(e, i.globals, i.strings)
+		}
+		return i.evaluate(e)
+	})
+}
+
+func (i *Interpreter) Execute(statements []Stmt) (any, error) {
+	return i.ExecuteContext(context.Background(), statements)
+}
+
+// ExecuteContext runs statements in order, stopping at the first runtime
+// error that isn't caught. The result is the value of the last statement if
+// it is an expression statement, and nil otherwise. Cancellation and limits
+// behave as they do for InterpretContext.
+func (i *Interpreter) ExecuteContext(ctx context.Context, statements []Stmt) (any, error) {
+	return i.run(ctx, func() (Value, error) {
+		// Code run from a local scope, such as by a debugger while the
+		// program is paused, isn't resolved, and finds variables by name.
+		if i.environment == i.globals {
+			i.strings = newStringTable()
+			var err error
+			if statements, err = resolve(statements, i.globals, i.strings); err != nil {
+				return nil, err
+			}
+		}
+		var value Value
+		for _, stmt := range statements {
+			var err error
+			value, err = i.execute(stmt)
+			if err != nil {
+				return nil, err
+			}
+		}
+		return value, nil
+	})
+}
+
+func (i *Interpreter) run(ctx context.Context, body func() (Value, error)) (result any, err error) {
+	if i.running == 0 {
+		i.startLimits(ctx)
+	}
+	i.running++
+
+	environment := i.environment
+	defer func() {
+		i.running--
+		i.environment = environment
+		if r := recover(); r != nil {
+			signal, ok := r.(haltSignal)
+			if !ok || i.running > 0 {
+				panic(r)
+			}
+			result, err = nil, signal.err
+		}
+	}()
+
+	result, err = body()
+	if t, ok := err.(*thrown); ok {
+		return nil, t.err
 	}
-	return i.result, nil
+	return result, err
 }
 
-func (i *Interpreter) evaluate(e Expr) {
-	e.Accept(i)
+// SetHook installs a Hook that is called before every expression is
+// evaluated. Passing nil removes it.
+func (i *Interpreter) SetHook(h Hook) {
+	i.hook = h
 }
 
-func (i *Interpreter) VisitLiteral(l Literal) {
-	i.result = l.literal
+// Frames returns the expressions currently being evaluated, outermost first.
+func (i *Interpreter) Frames() []Frame {
+	return slices.Clone(i.frames)
 }
 
-func (i *Interpreter) VisitGroup(g Group) {
-	i.evaluate(g.expr)
+func (i *Interpreter) evaluate(e Expr) (Value, error) {
+	line := lineOf(e)
+	i.frames = append(i.frames, Frame{Expr: e, Line: line})
+	defer func() { i.frames = i.frames[:len(i.frames)-1] }()
+
+	i.checkLimits(line)
+	if i.hook != nil {
+		i.hook.BeforeExpr(i.frames)
+	}
+	mark := i.tracer.mark()
+	value, err := Accept[Value](e, i)
+	if err == nil {
+		i.tracer.expr(e, mark, value)
+	}
+	return value, err
 }
 
-func (i *Interpreter) VisitBinary(b Binary) {
-	current := i.result
-	i.evaluate(b.left)
-	l := i.result
-	i.evaluate(b.right)
-	r := i.result
-	i.result = current
+func (i *Interpreter) VisitLiteral(l Literal) (Value, error) {
+	return l.literal, nil
+}
 
-	switch b.operator.TokenType {
-	case BangEqual:
-		i.result = l != r
-	case EqualEqual:
-		i.result = l == r
-	case Greater:
-		l, r, ok := i.checkNumbers(b.operator, l, r)
-		if !ok {
-			return
+func (i *Interpreter) VisitGroup(g Group) (Value, error) {
+	return i.evaluate(g.expr)
+}
+
+func (i *Interpreter) VisitBinary(b Binary) (Value, error) {
+	l, err := i.evaluate(b.left)
+	if err != nil {
+		return nil, err
+	}
+	r, err := i.evaluate(b.right)
+	if err != nil {
+		return nil, err
+	}
+
+	// Concatenation is the one operation that allocates, so it stays here
+	// rather than in binaryOperation.
+	if b.operator.TokenType == Plus {
+		lStr, lOk := l.(string)
+		rStr, rOk := r.(string)
+		if lOk && rOk {
+			i.allocate(len(lStr)+len(rStr), b.operator.Line)
+			return i.concat(lStr, rStr), nil
 		}
-		i.result = l > r
-	case GreaterEqual:
-		l, r, ok := i.checkNumbers(b.operator, l, r)
-		if !ok {
-			return
+	}
+
+	value, err := binaryOperation(b.operator, l, r)
+	if err != nil {
+		return nil, i.reportError(err, b.operator)
+	}
+	return value, nil
+}
+
+func (i *Interpreter) VisitUnary(u Unary) (Value, error) {
+	r, err := i.evaluate(u.right)
+	if err != nil {
+		return nil, err
+	}
+
+	value, err := unaryOperation(u.operator, r)
+	if err != nil {
+		return nil, i.reportError(err, u.operator)
+	}
+	return value, nil
+}
+
+func (i *Interpreter) VisitVariable(v Variable) (Value, error) {
+	if v.binding != nil {
+		if value, ok := i.bound(v.binding).getAt(v.binding.slot); ok {
+			return value, nil
 		}
-		i.result = l >= r
-	case Less:
-		l, r, ok := i.checkNumbers(b.operator, l, r)
-		if !ok {
-			return
+	}
+	value, err := i.environment.Get(v.name)
+	if err != nil {
+		return nil, i.reportError(err, v.name)
+	}
+	return value, nil
+}
+
+func (i *Interpreter) VisitCall(c Call) (Value, error) {
+	callee, err := i.evaluate(c.callee)
+	if err != nil {
+		return nil, err
+	}
+
+	args := make([]Value, 0, len(c.arguments))
+	for _, arg := range c.arguments {
+		value, err := i.evaluate(arg)
+		if err != nil {
+			return nil, err
 		}
-		i.result = l < r
-	case LessEqual:
-		l, r, ok := i.checkNumbers(b.operator, l, r)
-		if !ok {
-			return
+		args = append(args, value)
+	}
+
+	function, ok := callee.(Callable)
+	if !ok {
+		return nil, i.reportError(errors.New("can only call functions"), c.paren)
+	}
+
+	result, err := i.call(function, args)
+	if err != nil {
+		// Errors thrown by Lox code the function called back into are
+		// passed through as they are.
+		if _, ok := err.(*thrown); ok {
+			return nil, err
 		}
-		i.result = l <= r
-	case Minus:
-		l, r, ok := i.checkNumbers(b.operator, l, r)
-		if !ok {
-			return
+		return nil, i.reportError(err, c.paren)
+	}
+	return result, nil
+}
+
+// call invokes function after checking that it accepts len(args) arguments.
+func (i *Interpreter) call(function Callable, args []Value) (Value, error) {
+	if arity := function.Arity(); arity >= 0 && arity != len(args) {
+		return nil, fmt.Errorf("expected %d arguments but got %d", arity, len(args))
+	}
+	i.enterCall()
+	defer i.exitCall()
+	return function.Call(i, args)
+}
+
+func (i *Interpreter) VisitAssign(a Assign) (Value, error) {
+	value, err := i.evaluate(a.value)
+	if err != nil {
+		return nil, err
+	}
+	if a.binding != nil && i.bound(a.binding).assignAt(a.binding.slot, value) {
+		return value, nil
+	}
+	if err := i.environment.Assign(a.name, value); err != nil {
+		return nil, i.reportError(err, a.name)
+	}
+	return value, nil
+}
+
+func (i *Interpreter) VisitList(l ListLiteral) (Value, error) {
+	elements, err := i.evaluateAll(l.elements)
+	if err != nil {
+		return nil, err
+	}
+
+	i.allocate(len(elements)*listElementSize, l.bracket.Line)
+	return NewList(elements...), nil
+}
+
+func (i *Interpreter) VisitMap(m MapLiteral) (Value, error) {
+	result := NewMap()
+	for idx, keyExpr := range m.keys {
+		key, err := i.evaluate(keyExpr)
+		if err != nil {
+			return nil, err
 		}
-		i.result = l - r
-	case Slash:
-		l, r, ok := i.checkNumbers(b.operator, l, r)
-		if !ok {
-			return
+		value, err := i.evaluate(m.values[idx])
+		if err != nil {
+			return nil, err
 		}
-		i.result = l / r
-	case Star:
-		l, r, ok := i.checkNumbers(b.operator, l, r)
-		if !ok {
-			return
+		if err := checkKey(key); err != nil {
+			return nil, i.reportError(err, m.brace)
 		}
-		i.result = l * r
-	case Plus:
-		// Try numbers first
-		if lNum, lOk := l.(float64); lOk {
-			if rNum, rOk := r.(float64); rOk {
-				i.result = lNum + rNum
-				return
-			}
+		result.Set(key, value)
+	}
+
+	i.allocate(result.Len()*mapEntrySize, m.brace.Line)
+	return result, nil
+}
+
+func (i *Interpreter) VisitIndex(ix Index) (Value, error) {
+	operands, err := i.evaluateAll([]Expr{ix.object, ix.index})
+	if err != nil {
+		return nil, err
+	}
+	object, index := operands[0], operands[1]
+
+	switch object := object.(type) {
+	case *List:
+		n, err := i.checkIndex(ix.bracket, object, index)
+		if err != nil {
+			return nil, err
 		}
-		// Try strings
-		if lStr, lOk := l.(string); lOk {
-			if rStr, rOk := r.(string); rOk {
-				i.result = lStr + rStr
-				return
-			}
+		return object.elements[n], nil
+	case *Map:
+		if err := checkKey(index); err != nil {
+			return nil, i.reportError(err, ix.bracket)
 		}
-		// Both failed, report error
-		err := fmt.Errorf("operands to %s must both be numbers or strings", b.operator.Lexeme)
-		i.reportError(err, b.operator)
+		// Missing keys read as nil; use has() to tell them apart.
+		value, _ := object.Get(index)
+		return value, nil
 	}
+	return nil, i.reportError(fmt.Errorf("can only index lists and maps, got %s", TypeOf(object)), ix.bracket)
 }
 
-func (i *Interpreter) VisitUnary(u Unary) {
-	current := i.result
-	i.evaluate(u.right)
-	r := i.result
-	i.result = current
+func (i *Interpreter) VisitSetIndex(s SetIndex) (Value, error) {
+	operands, err := i.evaluateAll([]Expr{s.object, s.index, s.value})
+	if err != nil {
+		return nil, err
+	}
+	object, index, value := operands[0], operands[1], operands[2]
 
-	switch u.operator.TokenType {
-	case Minus:
-		n, ok := i.checkNumber(u.operator, r)
-		if !ok {
-			return
+	switch object := object.(type) {
+	case *List:
+		n, err := i.checkIndex(s.bracket, object, index)
+		if err != nil {
+			return nil, err
 		}
-		i.result = -n
-	case Bang:
-		i.result = !isTruthy(r)
+		object.elements[n] = value
+	case *Map:
+		if err := checkKey(index); err != nil {
+			return nil, i.reportError(err, s.bracket)
+		}
+		if object.Set(index, value) {
+			i.allocate(mapEntrySize, s.bracket.Line)
+		}
+	default:
+		return nil, i.reportError(fmt.Errorf("can only index lists and maps, got %s", TypeOf(object)), s.bracket)
+	}
+	return value, nil
+}
+
+func (i *Interpreter) VisitGet(g Get) (Value, error) {
+	object, err := i.evaluate(g.object)
+	if err != nil {
+		return nil, err
+	}
+
+	var method *NativeFunction
+	var ok bool
+	switch object := object.(type) {
+	case *List:
+		method, ok = object.method(g.name.Lexeme)
+	case *Map:
+		method, ok = object.method(g.name.Lexeme)
+	case *ErrorValue:
+		if value, ok := object.property(g.name.Lexeme); ok {
+			return value, nil
+		}
+	default:
+		return nil, i.reportError(fmt.Errorf("only lists, maps and errors have properties, got %s", TypeOf(object)), g.name)
+	}
+	if !ok {
+		return nil, i.reportError(fmt.Errorf("undefined property '%s'", g.name.Lexeme), g.name)
+	}
+	return method, nil
+}
+
+// evaluateAll evaluates exprs from left to right.
+func (i *Interpreter) evaluateAll(exprs []Expr) ([]Value, error) {
+	values := make([]Value, 0, len(exprs))
+	for _, expr := range exprs {
+		value, err := i.evaluate(expr)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+	}
+	return values, nil
+}
+
+// execute runs s, returning its value if it is an expression statement and
+// nil otherwise.
+func (i *Interpreter) execute(s Stmt) (Value, error) {
+	mark := i.tracer.mark()
+	defer i.tracer.unwind(mark)
+	return AcceptStmt[Value](s, i)
+}
+
+func (i *Interpreter) executeBlock(statements []Stmt, environment *Environment) error {
+	previous := i.environment
+	i.environment = environment
+	defer func() { i.environment = previous }()
+
+	for _, stmt := range statements {
+		if _, err := i.execute(stmt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (i *Interpreter) VisitExpressionStmt(s ExpressionStmt) (Value, error) {
+	value, err := i.evaluate(s.expr)
+	if err != nil {
+		return nil, err
+	}
+	i.tracer.stmt(s)
+	return value, nil
+}
+
+func (i *Interpreter) VisitPrintStmt(s PrintStmt) (Value, error) {
+	value, err := i.evaluate(s.expr)
+	if err != nil {
+		return nil, err
+	}
+	i.tracer.stmt(s)
+	fmt.Fprintln(i.stdout, i.stringify(value, s.keyword.Line))
+	return nil, nil
+}
+
+func (i *Interpreter) VisitVarStmt(s VarStmt) (Value, error) {
+	var value Value
+	if s.initializer != nil {
+		var err error
+		if value, err = i.evaluate(s.initializer); err != nil {
+			return nil, err
+		}
+	}
+	i.tracer.stmt(s)
+	if s.binding != nil {
+		i.bound(s.binding).defineAt(s.binding.slot, s.name.Lexeme, value)
+		return nil, nil
+	}
+	i.environment.Define(s.name.Lexeme, value)
+	return nil, nil
+}
+
+// bound returns the environment that holds the variable bound to b. If its
+// slot there hasn't been defined yet, callers fall back to finding the
+// variable by name, which reaches the built-ins and reports undefined
+// variables as before.
+func (i *Interpreter) bound(b *binding) *Environment {
+	if b.global {
+		return i.globals
+	}
+	return i.environment.ancestor(b.depth)
+}
+
+func (i *Interpreter) VisitBlockStmt(s BlockStmt) (Value, error) {
+	return nil, i.executeBlock(s.statements, NewEnvironment(i.environment))
+}
+
+func (i *Interpreter) VisitThrowStmt(s ThrowStmt) (Value, error) {
+	value, err := i.evaluate(s.value)
+	if err != nil {
+		return nil, err
+	}
+	i.tracer.stmt(s)
+	return nil, i.throw(value, uncaught(value, s.keyword.Line))
+}
+
+func (i *Interpreter) VisitTryStmt(s TryStmt) (Value, error) {
+	err := i.executeBlock(s.body, NewEnvironment(i.environment))
+
+	if t, ok := err.(*thrown); ok && s.catchBody != nil {
+		environment := NewEnvironment(i.environment)
+		environment.Define(s.catchName.Lexeme, t.value)
+		err = i.executeBlock(s.catchBody, environment)
 	}
+
+	if s.finallyBody != nil {
+		if finallyErr := i.executeBlock(s.finallyBody, NewEnvironment(i.environment)); finallyErr != nil {
+			return nil, finallyErr
+		}
+	}
+	return nil, err
 }
 
-func (i *Interpreter) reportError(err error, token Token) {
-	err = fmt.Errorf("[line %d] %w: %s", token.Line, ErrLoxRuntime, err.Error())
-	i.errors = append(i.errors, err)
+func (i *Interpreter) VisitIfStmt(s IfStmt) (Value, error) {
+	condition, err := i.evaluate(s.condition)
+	if err != nil {
+		return nil, err
+	}
+	i.tracer.stmt(s)
+	if isTruthy(condition) {
+		_, err = i.execute(s.thenBranch)
+	} else if s.elseBranch != nil {
+		_, err = i.execute(s.elseBranch)
+	}
+	return nil, err
+}
+
+// VisitForInStmt iterates over the elements of a list or the keys of a map.
+func (i *Interpreter) VisitForInStmt(s ForInStmt) (Value, error) {
+	iterable, err := i.evaluate(s.iterable)
+	if err != nil {
+		return nil, err
+	}
+
+	// A list's length is checked on every pass so that the body can grow or
+	// shrink it. A map's keys are taken before the loop starts, so that the
+	// body can add and remove keys without others being skipped; keys it
+	// removes before the loop reaches them are passed over.
+	var next func() (Value, bool)
+	switch iterable := iterable.(type) {
+	case *List:
+		n := 0
+		next = func() (Value, bool) {
+			if n >= iterable.Len() {
+				return nil, false
+			}
+			n++
+			return iterable.elements[n-1], true
+		}
+	case *Map:
+		keys := iterable.Keys()
+		next = func() (Value, bool) {
+			for len(keys) > 0 {
+				key := keys[0]
+				keys = keys[1:]
+				if _, ok := iterable.Get(key); ok {
+					return key, true
+				}
+			}
+			return nil, false
+		}
+	default:
+		return nil, i.reportError(fmt.Errorf("can only iterate over lists and maps, got %s", TypeOf(iterable)), s.name)
+	}
+
+	for element, ok := next(); ok; element, ok = next() {
+		i.tracer.stmt(s)
+		environment := NewEnvironment(i.environment)
+		environment.Define(s.name.Lexeme, element)
+		if err := i.executeBlock([]Stmt{s.body}, environment); err != nil {
+			return nil, err
+		}
+	}
+	return nil, nil
+}
+
+// reportError builds the runtime error reported at token. The error is
+// returned up the Go stack as a thrown *ErrorValue, so the nearest try
+// statement can catch it; otherwise it ends the program.
+func (i *Interpreter) reportError(err error, token Token) error {
+	loxErr := &Error{Line: token.Line, Message: err.Error(), kind: ErrLoxRuntime}
+	return i.throw(&ErrorValue{message: loxErr.Message, line: loxErr.Line}, loxErr)
 }
 
 func isTruthy(object any) bool {
@@ -146,38 +597,91 @@ func isTruthy(object any) bool {
 	}
 }
 
-func (i *Interpreter) checkNumber(operator Token, object any) (float64, bool) {
-	n, ok := object.(float64)
-	if ok {
-		return n, true
+// binaryOperation applies operator to two already evaluated operands. It
+// needs no interpreter state, so the optimizer folds constants with it too.
+func binaryOperation(operator Token, l, r Value) (Value, error) {
+	switch operator.TokenType {
+	case BangEqual:
+		return l != r, nil
+	case EqualEqual:
+		return l == r, nil
+	case Plus:
+		if lStr, lOk := l.(string); lOk {
+			if rStr, rOk := r.(string); rOk {
+				return lStr + rStr, nil
+			}
+		}
+		lNum, lOk := l.(float64)
+		rNum, rOk := r.(float64)
+		if !lOk || !rOk {
+			return nil, fmt.Errorf("operands to %s must both be numbers or strings", operator.Lexeme)
+		}
+		return lNum + rNum, nil
+	}
+
+	ln, lok := l.(float64)
+	rn, rok := r.(float64)
+	if !lok || !rok {
+		return nil, fmt.Errorf("operands to %s must both be numbers", operator.Lexeme)
 	}
-	err := fmt.Errorf("operand to %s must be a number", operator.Lexeme)
-	i.reportError(err, operator)
-	return 0, false
-}
 
-func (i *Interpreter) checkNumbers(operator Token, left, right any) (float64, float64, bool) {
-	l, lok := left.(float64)
-	r, rok := right.(float64)
+	switch operator.TokenType {
+	case Greater:
+		return ln > rn, nil
+	case GreaterEqual:
+		return ln >= rn, nil
+	case Less:
+		return ln < rn, nil
+	case LessEqual:
+		return ln <= rn, nil
+	case Minus:
+		return ln - rn, nil
+	case Slash:
+		return ln / rn, nil
+	case Star:
+		return ln * rn, nil
+	}
+	return nil, nil
+}
 
-	if lok && rok {
-		return l, r, true
+// unaryOperation applies operator to an already evaluated operand.
+func unaryOperation(operator Token, r Value) (Value, error) {
+	switch operator.TokenType {
+	case Minus:
+		n, ok := r.(float64)
+		if !ok {
+			return nil, fmt.Errorf("operand to %s must be a number", operator.Lexeme)
+		}
+		return -n, nil
+	case Bang:
+		return !isTruthy(r), nil
 	}
+	return nil, nil
+}
 
-	err := fmt.Errorf("operands to %s must both be numbers", operator.Lexeme)
-	i.reportError(err, operator)
-	return 0, 0, false
+// checkIndex reports an error unless index is an integer within the bounds
+// of list.
+func (i *Interpreter) checkIndex(bracket Token, list *List, index any) (int, error) {
+	n, ok := index.(float64)
+	if !ok || n != math.Trunc(n) {
+		return 0, i.reportError(fmt.Errorf("list index must be an integer, got %s", Stringify(index)), bracket)
+	}
+	// Compared as a float, since converting an index too large for an int
+	// gives a meaningless one.
+	if n < 0 || n >= float64(list.Len()) {
+		return 0, i.reportError(fmt.Errorf("list index %s out of bounds for a list of length %d", Stringify(n), list.Len()), bracket)
+	}
+	return int(n), nil
 }
 
-func (i *Interpreter) checkStrings(operator Token, left, right any) (string, string, bool) {
+func (i *Interpreter) checkStrings(operator Token, left, right any) (string, string, error) {
 	l, lok := left.(string)
 	r, rok := right.(string)
 
 	if lok && rok {
-		return l, r, true
+		return l, r, nil
 	}
 
 	err := fmt.Errorf("operands to %s must both be strings", operator.Lexeme)
-	i.reportError(err, operator)
-	return "", "", false
+	return "", "", i.reportError(err, operator)
 }
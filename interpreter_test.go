@@ -3,6 +3,12 @@
 package lox
 
 import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -799,6 +805,58 @@ func TestInterpreter_TypeErrors(t *testing.T) {
 	}
 }
 
+func TestInterpreter_HaltsOnFirstError(t *testing.T) {
+	tests := []struct {
+		name     string
+		source   string
+		output   string
+		errorMsg string
+	}{
+		{
+			name:     "error inside an expression",
+			source:   `(1 + "a") * 2`,
+			errorMsg: "[line 1] runtime error: operands to + must both be numbers or strings",
+		},
+		{
+			name:     "later operands are not evaluated",
+			source:   "-nil + missing",
+			errorMsg: "[line 1] runtime error: operand to - must be a number",
+		},
+		{
+			name:     "later statements are not run",
+			source:   "print 1;\nprint -true;\nprint 2;",
+			output:   "1\n",
+			errorMsg: "[line 2] runtime error: operand to - must be a number",
+		},
+		{
+			name:     "loops stop",
+			source:   "for x in [1, nil, 3] print -x;",
+			output:   "-1\n",
+			errorMsg: "[line 1] runtime error: operand to - must be a number",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			asrt := assert.New(t)
+			var out bytes.Buffer
+			interp := NewInterpreter(WithStdout(&out))
+			tokens, err := NewScanner(tt.source).ScanTokens()
+			asrt.NoError(err)
+			statements, err := NewParser(tokens).ParseProgram()
+			asrt.NoError(err)
+
+			result, err := interp.Execute(statements)
+			asrt.Nil(result)
+			asrt.Len(Errors(err), 1)
+			asrt.EqualError(err, tt.errorMsg)
+			asrt.Equal(tt.output, out.String())
+			asrt.Empty(interp.Frames())
+			asrt.Same(interp.Globals(), interp.Environment())
+		})
+	}
+}
+
 func TestInterpreter_Truthiness(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -874,3 +932,151 @@ func TestInterpreter_Truthiness(t *testing.T) {
 		})
 	}
 }
+
+func TestInterpreter_VariablesAndCalls(t *testing.T) {
+	add := NewNativeFunction("add", 2, func(args []Value) (Value, error) {
+		return args[0].(float64) + args[1].(float64), nil
+	})
+	fail := NewNativeFunction("fail", 0, func(args []Value) (Value, error) {
+		return nil, errors.New("native failure")
+	})
+
+	tests := []struct {
+		name     string
+		expr     Expr
+		expected any
+		errorMsg string
+	}{
+		{
+			name:     "variable: defined global",
+			expr:     Variable{name: NewToken(Identifier, "answer", nil, 1)},
+			expected: 42.0,
+		},
+		{
+			name:     "variable: undefined",
+			expr:     Variable{name: NewToken(Identifier, "missing", nil, 3)},
+			errorMsg: "[line 3] runtime error: undefined variable 'missing'",
+		},
+		{
+			name: "call: native function",
+			expr: Call{
+				callee:    Variable{name: NewToken(Identifier, "add", nil, 1)},
+				paren:     NewToken(RightParen, ")", nil, 1),
+				arguments: []Expr{Literal{literal: 1.0}, Variable{name: NewToken(Identifier, "answer", nil, 1)}},
+			},
+			expected: 43.0,
+		},
+		{
+			name: "call: wrong number of arguments",
+			expr: Call{
+				callee:    Variable{name: NewToken(Identifier, "add", nil, 1)},
+				paren:     NewToken(RightParen, ")", nil, 2),
+				arguments: []Expr{Literal{literal: 1.0}},
+			},
+			errorMsg: "[line 2] runtime error: expected 2 arguments but got 1",
+		},
+		{
+			name: "call: non-callable",
+			expr: Call{
+				callee: Literal{literal: "add"},
+				paren:  NewToken(RightParen, ")", nil, 1),
+			},
+			errorMsg: "[line 1] runtime error: can only call functions",
+		},
+		{
+			name: "call: native error reported at call site",
+			expr: Call{
+				callee: Variable{name: NewToken(Identifier, "fail", nil, 4)},
+				paren:  NewToken(RightParen, ")", nil, 4),
+			},
+			errorMsg: "[line 4] runtime error: native failure",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			asrt := assert.New(t)
+			interp := NewInterpreter()
+			interp.Globals().Define("answer", 42.0)
+			interp.Globals().Define("add", add)
+			interp.Globals().Define("fail", fail)
+			result, err := interp.Interpret(tt.expr)
+
+			if tt.errorMsg != "" {
+				asrt.ErrorIs(err, ErrLoxRuntime)
+				asrt.EqualError(err, tt.errorMsg)
+				return
+			}
+
+			asrt.NoError(err)
+			asrt.Equal(tt.expected, result)
+		})
+	}
+}
+
+func TestInterpreter_If(t *testing.T) {
+	tests := []struct {
+		name     string
+		source   string
+		output   string
+		errorMsg string
+	}{
+		{name: "true condition", source: `if (true) print "then";`, output: "then\n"},
+		{name: "false condition", source: `if (false) print "then";`, output: ""},
+		{name: "else", source: `if (nil) print "then"; else print "else";`, output: "else\n"},
+		{name: "truthy values", source: `if (0) print "zero"; if ("") print "empty";`, output: "zero\nempty\n"},
+		{name: "block branches", source: `var x = 1; if (x == 1) { var y = 2; print y; } print x;`, output: "2\n1\n"},
+		{name: "condition error", source: "if (-\"a\") print 1;", errorMsg: "[line 1] runtime error: operand to - must be a number"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			asrt := assert.New(t)
+			var out bytes.Buffer
+			_, err := NewVM(WithStdout(&out)).Eval(tt.source)
+
+			if tt.errorMsg != "" {
+				asrt.EqualError(err, tt.errorMsg)
+				return
+			}
+			asrt.NoError(err)
+			asrt.Equal(tt.output, out.String())
+		})
+	}
+}
+
+func TestInterpreter_Streams(t *testing.T) {
+	asrt := assert.New(t)
+	var stdout, stderr bytes.Buffer
+	stdin := strings.NewReader("typed input\n")
+	interp := NewInterpreter(WithStdout(&stdout), WithStderr(&stderr), WithStdin(stdin))
+
+	echo := NewNativeFunction("echo", 0, func(args []Value) (Value, error) {
+		line, err := bufio.NewReader(interp.Stdin()).ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		fmt.Fprint(interp.Stdout(), line)
+		fmt.Fprint(interp.Stderr(), "echoed\n")
+		return nil, nil
+	})
+	interp.Globals().Define("echo", echo)
+
+	_, err := interp.Interpret(Call{
+		callee: Variable{name: NewToken(Identifier, "echo", nil, 1)},
+		paren:  NewToken(RightParen, ")", nil, 1),
+	})
+
+	asrt.NoError(err)
+	asrt.Equal("typed input\n", stdout.String())
+	asrt.Equal("echoed\n", stderr.String())
+}
+
+func TestInterpreter_DefaultStreams(t *testing.T) {
+	asrt := assert.New(t)
+	interp := NewInterpreter()
+
+	asrt.Equal(os.Stdout, interp.Stdout())
+	asrt.Equal(os.Stderr, interp.Stderr())
+	asrt.Equal(os.Stdin, interp.Stdin())
+}
@@ -0,0 +1,176 @@
+package lox
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+var (
+	ErrStepLimitExceeded   = fmt.Errorf("%w: step limit exceeded", ErrLoxRuntime)
+	ErrCallDepthExceeded   = fmt.Errorf("%w: call depth exceeded", ErrLoxRuntime)
+	ErrTimeLimitExceeded   = fmt.Errorf("%w: time limit exceeded", ErrLoxRuntime)
+	ErrCanceled            = fmt.Errorf("%w: execution canceled", ErrLoxRuntime)
+	ErrMemoryLimitExceeded = fmt.Errorf("%w: memory limit exceeded", ErrLoxRuntime)
+)
+
+// limitCheckInterval is how many steps pass between checks of the context and
+// the time limit, which are too expensive to make on every step.
+const limitCheckInterval = 256
+
+// WithStepLimit stops evaluation after n expressions have been evaluated.
+func WithStepLimit(n int) Option {
+	return func(i *Interpreter) {
+		i.stepLimit = n
+	}
+}
+
+// WithMaxCallDepth stops evaluation when more than n calls are in progress
+// at once, bounding how deeply functions can recurse. Calls count whether
+// Lox code or a native function makes them, such as the callback a list's
+// map method calls for each element.
+func WithMaxCallDepth(n int) Option {
+	return func(i *Interpreter) {
+		i.maxCallDepth = n
+	}
+}
+
+// WithTimeLimit stops evaluation once it has run for longer than d.
+func WithTimeLimit(d time.Duration) Option {
+	return func(i *Interpreter) {
+		i.timeLimit = d
+	}
+}
+
+// WithMemoryLimit stops evaluation once the values it has allocated add up to
+// more than n bytes. Memory is never credited back, so the limit bounds the
+// total allocated by a single evaluation rather than what is live at once.
+func WithMemoryLimit(n int) Option {
+	return func(i *Interpreter) {
+		i.memoryLimit = n
+	}
+}
+
+// limits tracks the resources used by a single evaluation. An evaluation
+// started while another is in progress, such as by a native function that
+// calls back into the interpreter, is part of the outer one: it counts
+// against the same limits, and only the outer context can cancel it.
+type limits struct {
+	ctx       context.Context
+	deadline  time.Time
+	steps     int
+	calls     int
+	allocated int
+}
+
+// startLimits begins tracking the resources used by an evaluation. It is
+// called only for the outermost evaluation.
+func (i *Interpreter) startLimits(ctx context.Context) {
+	i.limits = limits{ctx: ctx}
+	if i.timeLimit > 0 {
+		i.limits.deadline = time.Now().Add(i.timeLimit)
+	}
+}
+
+// checkLimits is called before each expression is evaluated and halts the
+// interpreter if any limit has been exceeded.
+func (i *Interpreter) checkLimits(line int) {
+	i.limits.steps++
+	if i.stepLimit > 0 && i.limits.steps > i.stepLimit {
+		i.halt(&Error{Line: line, Message: fmt.Sprintf("executed %d steps", i.stepLimit), kind: ErrStepLimitExceeded})
+	}
+
+	if (i.limits.steps-1)%limitCheckInterval != 0 {
+		return
+	}
+	if err := i.limits.ctx.Err(); err != nil {
+		i.halt(&Error{Line: line, Message: err.Error(), kind: ErrCanceled})
+	}
+	if !i.limits.deadline.IsZero() && time.Now().After(i.limits.deadline) {
+		i.halt(&Error{Line: line, Message: fmt.Sprintf("ran for more than %s", i.timeLimit), kind: ErrTimeLimitExceeded})
+	}
+}
+
+// enterCall counts a call to a Callable, halting the interpreter if that
+// is more calls than the call depth limit allows to be in progress at once.
+// Each call must be matched by a call to exitCall when it returns.
+func (i *Interpreter) enterCall() {
+	i.limits.calls++
+	if i.maxCallDepth > 0 && i.limits.calls > i.maxCallDepth {
+		i.halt(&Error{Line: i.line(), Message: fmt.Sprintf("nested more than %d calls deep", i.maxCallDepth), kind: ErrCallDepthExceeded})
+	}
+}
+
+func (i *Interpreter) exitCall() {
+	i.limits.calls--
+}
+
+// line is the line of the innermost frame, or 0 when nothing is being
+// evaluated.
+func (i *Interpreter) line() int {
+	if len(i.frames) > 0 {
+		return i.frames[len(i.frames)-1].Line
+	}
+	return 0
+}
+
+// Allocate charges n bytes against the memory limit of the current
+// evaluation. Callables that build new values should call it before
+// allocating; if the limit is exceeded, evaluation stops with
+// ErrMemoryLimitExceeded reported at the line of the innermost frame. It must
+// only be called while the interpreter is evaluating.
+func (i *Interpreter) Allocate(n int) {
+	i.allocate(n, i.line())
+}
+
+// Allocated reports how many bytes the current or most recent evaluation has
+// allocated.
+func (i *Interpreter) Allocated() int {
+	return i.limits.allocated
+}
+
+// allocate charges n bytes for an allocation made at line, halting the
+// interpreter if that exceeds the memory limit.
+func (i *Interpreter) allocate(n int, line int) {
+	i.limits.allocated += n
+	if i.memoryLimit > 0 && i.limits.allocated > i.memoryLimit {
+		i.halt(&Error{
+			Line:    line,
+			Message: fmt.Sprintf("allocated %d bytes, limit is %d", i.limits.allocated, i.memoryLimit),
+			kind:    ErrMemoryLimitExceeded,
+		})
+	}
+}
+
+// stringify formats v as Stringify does, charging the string against the
+// memory limit for an allocation made at line.
+func (i *Interpreter) stringify(v Value, line int) string {
+	w := i.valueWriter()
+	w.value(v)
+	i.allocate(w.Len(), line)
+	return w.String()
+}
+
+// valueWriter returns a valueWriter that stops once it has written more
+// than the memory limit has left, so that a string far larger than the
+// limit, such as that of a list nested in itself many times over, is never
+// built. What it writes must be charged with allocate, which halts the
+// interpreter if the writer stopped early.
+func (i *Interpreter) valueWriter() *valueWriter {
+	w := &valueWriter{}
+	if i.memoryLimit > 0 {
+		w.limit = i.memoryLimit - i.limits.allocated + 1
+	}
+	return w
+}
+
+// haltSignal carries an error that must stop evaluation immediately. It is
+// raised with panic and recovered by the outermost evaluation, so that an
+// evaluation started from within another stops both.
+type haltSignal struct {
+	err error
+}
+
+func (i *Interpreter) halt(err error) {
+	panic(haltSignal{err: err})
+}
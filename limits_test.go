@@ -0,0 +1,285 @@
+// ABOUTME: Tests for context-aware evaluation and the interpreter's resource limits
+// ABOUTME: Covers cancellation, step, call depth, time and memory limits, and reentrant evaluation
+package lox
+
+import (
+	"context"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// chain builds an expression adding n copies of operand together.
+func chain(n int, operand Expr) Expr {
+	var expr Expr = operand
+	for range n - 1 {
+		expr = Binary{left: expr, right: operand, operator: NewToken(Plus, "+", nil, 1)}
+	}
+	return expr
+}
+
+func tickCall() Expr {
+	return Call{
+		callee: Variable{name: NewToken(Identifier, "tick", nil, 1)},
+		paren:  NewToken(RightParen, ")", nil, 1),
+	}
+}
+
+func TestInterpreter_Limits(t *testing.T) {
+	tests := []struct {
+		name     string
+		source   string
+		opts     []Option
+		expected Value
+		wantErr  error
+		errorMsg string
+	}{
+		{
+			name:     "within step limit",
+			source:   "1 + 2",
+			opts:     []Option{WithStepLimit(3)},
+			expected: 3.0,
+		},
+		{
+			name:     "step limit exceeded",
+			source:   "1 +\n2 + 3",
+			opts:     []Option{WithStepLimit(4)},
+			wantErr:  ErrStepLimitExceeded,
+			errorMsg: "[line 2] runtime error: step limit exceeded: executed 4 steps",
+		},
+		{
+			name:     "within call depth",
+			source:   "len([1, 2].map(str))",
+			opts:     []Option{WithMaxCallDepth(2)},
+			expected: 2.0,
+		},
+		{
+			name:     "call depth exceeded",
+			source:   "len([1, 2].map(str)\n)",
+			opts:     []Option{WithMaxCallDepth(1)},
+			wantErr:  ErrCallDepthExceeded,
+			errorMsg: "[line 1] runtime error: call depth exceeded: nested more than 1 calls deep",
+		},
+		{
+			name:     "nested expressions aren't calls",
+			source:   "-(-(-(-(len([1])))))",
+			opts:     []Option{WithMaxCallDepth(1)},
+			expected: 1.0,
+		},
+		{
+			name:     "runtime error stops evaluation before the limit",
+			source:   `-"a" + 1 + 2`,
+			opts:     []Option{WithStepLimit(5)},
+			wantErr:  ErrLoxRuntime,
+			errorMsg: "[line 1] runtime error: operand to - must be a number",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			asrt := assert.New(t)
+			interp := NewInterpreter(tt.opts...)
+			result, err := interp.Interpret(parseSource(t, tt.source))
+
+			if tt.wantErr != nil {
+				asrt.ErrorIs(err, tt.wantErr)
+				asrt.ErrorIs(err, ErrLoxRuntime)
+				asrt.EqualError(err, tt.errorMsg)
+				asrt.Nil(result)
+				asrt.Empty(interp.Frames())
+				return
+			}
+			asrt.NoError(err)
+			asrt.Equal(tt.expected, result)
+		})
+	}
+}
+
+func TestInterpreter_ReentrantLimits(t *testing.T) {
+	asrt := assert.New(t)
+	interp := NewInterpreter(WithStepLimit(10))
+	nested := 0
+	interp.Globals().Define("again", NewNativeFunction("again", 0, func(args []Value) (Value, error) {
+		nested++
+		return interp.Interpret(parseSource(t, "1 + 2"))
+	}))
+
+	_, err := interp.Interpret(parseSource(t, "again() + again() + again()"))
+	asrt.ErrorIs(err, ErrStepLimitExceeded, "steps taken by nested evaluations count against the outer one")
+	asrt.EqualError(err, "[line 1] runtime error: step limit exceeded: executed 10 steps")
+	asrt.Equal(2, nested)
+	asrt.Empty(interp.Frames())
+
+	result, err := interp.Interpret(parseSource(t, "again()"))
+	asrt.NoError(err, "the next evaluation starts afresh")
+	asrt.Equal(3.0, result)
+}
+
+func TestInterpreter_InterpretContextCanceled(t *testing.T) {
+	asrt := assert.New(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := NewInterpreter().InterpretContext(ctx, parseSource(t, "1 + 2"))
+	asrt.ErrorIs(err, ErrCanceled)
+	asrt.ErrorIs(err, ErrLoxRuntime)
+	asrt.EqualError(err, "[line 1] runtime error: execution canceled: context canceled")
+}
+
+func TestInterpreter_InterpretContextCanceledMidway(t *testing.T) {
+	asrt := assert.New(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	calls := 0
+	interp := NewInterpreter()
+	interp.Globals().Define("tick", NewNativeFunction("tick", 0, func(args []Value) (Value, error) {
+		calls++
+		cancel()
+		return 1.0, nil
+	}))
+
+	_, err := interp.InterpretContext(ctx, chain(1000, tickCall()))
+	asrt.ErrorIs(err, ErrCanceled)
+	asrt.Less(calls, 1000)
+}
+
+func TestInterpreter_TimeLimit(t *testing.T) {
+	asrt := assert.New(t)
+	interp := NewInterpreter(WithTimeLimit(20 * time.Millisecond))
+	interp.Globals().Define("tick", NewNativeFunction("tick", 0, func(args []Value) (Value, error) {
+		time.Sleep(time.Millisecond)
+		return 1.0, nil
+	}))
+
+	start := time.Now()
+	_, err := interp.Interpret(chain(2000, tickCall()))
+	asrt.ErrorIs(err, ErrTimeLimitExceeded)
+	asrt.True(strings.HasSuffix(err.Error(), "ran for more than 20ms"))
+	asrt.Less(time.Since(start), time.Second)
+}
+
+func TestInterpreter_MemoryLimit(t *testing.T) {
+	tests := []struct {
+		name     string
+		source   string
+		limit    int
+		expected Value
+		errorMsg string
+	}{
+		{
+			name:     "within limit",
+			source:   `"ab" + "cd" + "ef"`,
+			limit:    10,
+			expected: "abcdef",
+		},
+		{
+			name:     "concatenation exceeds limit",
+			source:   `"ab" + "cd" +` + "\n" + `"ef"`,
+			limit:    9,
+			errorMsg: "[line 1] runtime error: memory limit exceeded: allocated 10 bytes, limit is 9",
+		},
+		{
+			name:     "native allocation exceeds limit",
+			source:   `"ab" +` + "\n" + `repeat("x", 20)`,
+			limit:    16,
+			errorMsg: "[line 2] runtime error: memory limit exceeded: allocated 20 bytes, limit is 16",
+		},
+		{
+			name:     "numbers do not allocate",
+			source:   "1 + 2 + 3",
+			limit:    1,
+			expected: 6.0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			asrt := assert.New(t)
+			interp := NewInterpreter(WithMemoryLimit(tt.limit))
+			interp.Globals().Define("repeat", &repeatFunction{})
+
+			result, err := interp.Interpret(parseSource(t, tt.source))
+			if tt.errorMsg != "" {
+				asrt.ErrorIs(err, ErrMemoryLimitExceeded)
+				asrt.ErrorIs(err, ErrLoxRuntime)
+				asrt.EqualError(err, tt.errorMsg)
+				asrt.Nil(result)
+				return
+			}
+			asrt.NoError(err)
+			asrt.Equal(tt.expected, result)
+		})
+	}
+}
+
+func TestInterpreter_MemoryLimitFormatting(t *testing.T) {
+	// a is a list nested 21 times, whose string is 6 GB long.
+	nested := "var a = [1]; for n in [" + zeros(21) + "] a = [a, a];\n"
+	tests := []struct {
+		name     string
+		source   string
+		output   string
+		errorMsg string
+	}{
+		{name: "str", source: nested + "str(a);", errorMsg: "[line 2] runtime error: memory limit exceeded: allocated 102401 bytes, limit is 102400"},
+		{name: "join", source: nested + "join([a, a], \", \");", errorMsg: "[line 2] runtime error: memory limit exceeded: allocated 102401 bytes, limit is 102400"},
+		{name: "print", source: nested + "print a;", errorMsg: "[line 2] runtime error: memory limit exceeded: allocated 102401 bytes, limit is 102400"},
+		{name: "within limit", source: `var xs = ["ab", 1]; print xs; print str(xs) + join(xs, "-");`, output: "[\"ab\", 1]\n[\"ab\", 1]ab-1\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			asrt := assert.New(t)
+			var out strings.Builder
+			var before, after runtime.MemStats
+			runtime.ReadMemStats(&before)
+			_, err := NewVM(WithStdout(&out), WithMemoryLimit(100*1024)).Eval(tt.source)
+			runtime.ReadMemStats(&after)
+
+			asrt.Less(after.TotalAlloc-before.TotalAlloc, uint64(10<<20), "formatting stops at the limit")
+			asrt.Equal(tt.output, out.String())
+			if tt.errorMsg != "" {
+				asrt.ErrorIs(err, ErrMemoryLimitExceeded)
+				asrt.EqualError(err, tt.errorMsg)
+				return
+			}
+			asrt.NoError(err)
+		})
+	}
+}
+
+func TestInterpreter_PrintAllocates(t *testing.T) {
+	asrt := assert.New(t)
+	var out strings.Builder
+	interp := NewInterpreter(WithStdout(&out))
+	_, err := interp.Execute(parseProgram(t, `print "abc"; print 12;`))
+	asrt.NoError(err)
+	asrt.Equal(5, interp.Allocated())
+}
+
+func TestInterpreter_AllocatedResetsBetweenRuns(t *testing.T) {
+	asrt := assert.New(t)
+	interp := NewInterpreter(WithMemoryLimit(6))
+	for range 3 {
+		_, err := interp.Interpret(parseSource(t, `"abc" + "def"`))
+		asrt.NoError(err)
+		asrt.Equal(6, interp.Allocated())
+	}
+}
+
+// repeatFunction is a native that accounts for the string it builds.
+type repeatFunction struct{}
+
+func (r *repeatFunction) Arity() int {
+	return 2
+}
+
+func (r *repeatFunction) Call(i *Interpreter, args []Value) (Value, error) {
+	s, n := args[0].(string), int(args[1].(float64))
+	i.Allocate(len(s) * n)
+	return strings.Repeat(s, n), nil
+}
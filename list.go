@@ -0,0 +1,125 @@
+package lox
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+)
+
+// listElementSize is the number of bytes charged against the memory limit for
+// each element stored in a list. An element is a reference to a value that
+// was charged for when it was made, so only the reference is charged here;
+// the cost of a value's size shows up when it is formatted as a string.
+const listElementSize = 16
+
+// List is a mutable, ordered collection of values. Lists are reference values:
+// copies share the same elements, and equality is identity.
+type List struct {
+	elements []Value
+}
+
+func NewList(elements ...Value) *List {
+	return &List{elements: elements}
+}
+
+func (l *List) Len() int {
+	return len(l.elements)
+}
+
+// Elements returns the list's backing slice; changes to it are visible to Lox.
+func (l *List) Elements() []Value {
+	return l.elements
+}
+
+func (l *List) String() string {
+	return Stringify(l)
+}
+
+// method returns the built-in method called name, bound to l.
+func (l *List) method(name string) (*NativeFunction, bool) {
+	switch name {
+	case "push":
+		return native("push", 1, func(i *Interpreter, args []Value) (Value, error) {
+			i.Allocate(listElementSize)
+			l.elements = append(l.elements, args[0])
+			return float64(len(l.elements)), nil
+		}), true
+	case "pop":
+		return native("pop", 0, func(_ *Interpreter, _ []Value) (Value, error) {
+			if len(l.elements) == 0 {
+				return nil, errors.New("pop() called on an empty list")
+			}
+			last := l.elements[len(l.elements)-1]
+			l.elements = l.elements[:len(l.elements)-1]
+			return last, nil
+		}), true
+	case "len":
+		return native("len", 0, func(_ *Interpreter, _ []Value) (Value, error) {
+			return float64(len(l.elements)), nil
+		}), true
+	case "slice":
+		return native("slice", 2, l.slice), true
+	case "map":
+		return native("map", 1, l.mapElements), true
+	case "filter":
+		return native("filter", 1, l.filter), true
+	}
+	return nil, false
+}
+
+// slice returns a new list holding the elements from start up to but not
+// including end.
+func (l *List) slice(i *Interpreter, args []Value) (Value, error) {
+	start, err := indexArg("slice", args[0])
+	if err != nil {
+		return nil, err
+	}
+	end, err := indexArg("slice", args[1])
+	if err != nil {
+		return nil, err
+	}
+	if start > end || end > len(l.elements) {
+		return nil, fmt.Errorf("slice() range %d..%d is out of bounds for a list of length %d", start, end, len(l.elements))
+	}
+
+	i.Allocate((end - start) * listElementSize)
+	return NewList(slices.Clone(l.elements[start:end])...), nil
+}
+
+func (l *List) mapElements(i *Interpreter, args []Value) (Value, error) {
+	fn, ok := args[0].(Callable)
+	if !ok {
+		return nil, argumentError("map", "a function", args[0])
+	}
+
+	i.Allocate(len(l.elements) * listElementSize)
+	elements := make([]Value, 0, len(l.elements))
+	for _, element := range l.elements {
+		result, err := i.call(fn, []Value{element})
+		if err != nil {
+			return nil, err
+		}
+		elements = append(elements, result)
+	}
+	return NewList(elements...), nil
+}
+
+func (l *List) filter(i *Interpreter, args []Value) (Value, error) {
+	fn, ok := args[0].(Callable)
+	if !ok {
+		return nil, argumentError("filter", "a function", args[0])
+	}
+
+	elements := []Value{}
+	for _, element := range l.elements {
+		keep, err := i.call(fn, []Value{element})
+		if err != nil {
+			return nil, err
+		}
+		if isTruthy(keep) {
+			elements = append(elements, element)
+		}
+	}
+	i.Allocate(len(elements) * listElementSize)
+	return NewList(elements...), nil
+}
@@ -0,0 +1,126 @@
+// ABOUTME: Tests for list values: literals, indexing, methods and for-in loops
+// ABOUTME: Runs Lox programs through the VM and checks results and runtime errors
+package lox
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestList(t *testing.T) {
+	tests := []struct {
+		name     string
+		source   string
+		expected string
+		errorMsg string
+	}{
+		// Literals and indexing
+		{name: "literal", source: `[1, "two", nil, true]`, expected: `[1, "two", nil, true]`},
+		{name: "empty literal", source: "[]", expected: "[]"},
+		{name: "nested literal", source: "[[1, 2], [3]]", expected: "[[1, 2], [3]]"},
+		{name: "index", source: `["a", "b", "c"][1]`, expected: "b"},
+		{name: "containing itself", source: "var xs = [1]; xs.push(xs); xs.push([xs]); xs", expected: "[1, [...], [[...]]]"},
+		{name: "containing another list twice", source: "var xs = [1]; [xs, xs]", expected: "[[1], [1]]"},
+		{name: "nested index", source: "[[1, 2], [3]][0][1]", expected: "2"},
+		{name: "set index", source: "var xs = [1, 2, 3]; xs[0] = 10; xs", expected: "[10, 2, 3]"},
+		{name: "set index result", source: "var xs = [1]; xs[0] = 5", expected: "5"},
+		{name: "lists are shared", source: "var xs = [1]; var ys = xs; ys[0] = 2; xs", expected: "[2]"},
+		{name: "index out of bounds", source: "[1, 2][2]", errorMsg: "[line 1] runtime error: list index 2 out of bounds for a list of length 2"},
+		{name: "negative index", source: "[1, 2][-1]", errorMsg: "[line 1] runtime error: list index -1 out of bounds for a list of length 2"},
+		{name: "fractional index", source: "[1, 2][0.5]", errorMsg: "[line 1] runtime error: list index must be an integer, got 0.5"},
+		{name: "string index", source: `[1, 2]["0"]`, errorMsg: "[line 1] runtime error: list index must be an integer, got 0"},
+		{name: "index non-list", source: "var x = 1;\nx[0]", errorMsg: "[line 2] runtime error: can only index lists and maps, got number"},
+		{name: "huge index", source: "[1, 2][100000000000000000000]", errorMsg: "[line 1] runtime error: list index 100000000000000000000 out of bounds for a list of length 2"},
+		{name: "huge negative index", source: "[1, 2][-100000000000000000000]", errorMsg: "[line 1] runtime error: list index -100000000000000000000 out of bounds for a list of length 2"},
+		{name: "set huge index", source: "var xs = [1, 2];\nxs[100000000000000000000] = 3", errorMsg: "[line 2] runtime error: list index 100000000000000000000 out of bounds for a list of length 2"},
+		{name: "set out of bounds", source: "var xs = [];\nxs[0] = 1", errorMsg: "[line 2] runtime error: list index 0 out of bounds for a list of length 0"},
+
+		// Methods
+		{name: "push", source: "var xs = [1]; xs.push(2); xs", expected: "[1, 2]"},
+		{name: "push returns length", source: "[1].push(2)", expected: "2"},
+		{name: "pop", source: "var xs = [1, 2]; xs.pop() + xs.len()", expected: "3"},
+		{name: "pop empty", source: "[].pop()", errorMsg: "[line 1] runtime error: pop() called on an empty list"},
+		{name: "len", source: "[1, 2, 3].len()", expected: "3"},
+		{name: "slice", source: "[1, 2, 3, 4].slice(1, 3)", expected: "[2, 3]"},
+		{name: "slice copies", source: "var xs = [1, 2]; var ys = xs.slice(0, 2); ys[0] = 9; xs", expected: "[1, 2]"},
+		{name: "slice out of bounds", source: "[1, 2].slice(1, 3)", errorMsg: "[line 1] runtime error: slice() range 1..3 is out of bounds for a list of length 2"},
+		{name: "slice huge end", source: "[1, 2].slice(0, 100000000000000000000)", errorMsg: "[line 1] runtime error: slice() index 100000000000000000000 is too large"},
+		{name: "slice infinite end", source: "[1, 2].slice(0, 1 / 0)", errorMsg: "[line 1] runtime error: slice() index +Inf is too large"},
+		{name: "slice fractional start", source: "[1, 2].slice(0.5, 1)", errorMsg: "[line 1] runtime error: slice() expects a non-negative integer but got number"},
+		{name: "map", source: "[1, 2].map(str)", expected: `["1", "2"]`},
+		{name: "filter", source: `["1", "x", "3"].filter(num)`, expected: `["1", "3"]`},
+		{name: "map: wrong arity", source: "[1].map(pow)", errorMsg: "[line 1] runtime error: expected 2 arguments but got 1"},
+		{name: "map: non-function", source: "[1].map(1)", errorMsg: "[line 1] runtime error: map() expects a function but got number"},
+		{name: "method value", source: "var push = [].push; push(1)", expected: "1"},
+		{name: "unknown method", source: "[].size", errorMsg: "[line 1] runtime error: undefined property 'size'"},
+		{name: "property of non-list", source: `"abc".len`, errorMsg: "[line 1] runtime error: only lists, maps and errors have properties, got string"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			asrt := assert.New(t)
+			result, err := NewVM().Eval(tt.source)
+
+			if tt.errorMsg != "" {
+				asrt.ErrorIs(err, ErrLoxRuntime)
+				asrt.EqualError(err, tt.errorMsg)
+				return
+			}
+			asrt.NoError(err)
+			asrt.Equal(tt.expected, Stringify(result))
+		})
+	}
+}
+
+func TestList_ForIn(t *testing.T) {
+	tests := []struct {
+		name     string
+		source   string
+		output   string
+		errorMsg string
+	}{
+		{name: "iterates in order", source: "for x in [1, 2, 3] print x;", output: "1\n2\n3\n"},
+		{name: "block body", source: `for x in ["a", "b"] { var y = x + x; print y; }`, output: "aa\nbb\n"},
+		{name: "empty list", source: "for x in [] print x;", output: ""},
+		{name: "loop variable is scoped", source: "var x = 0; for x in [1] print x; print x;", output: "1\n0\n"},
+		{name: "non-list", source: "for x in 3 print x;", errorMsg: "[line 1] runtime error: can only iterate over lists and maps, got number"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			asrt := assert.New(t)
+			var out bytes.Buffer
+			_, err := NewVM(WithStdout(&out)).Eval(tt.source)
+
+			if tt.errorMsg != "" {
+				asrt.EqualError(err, tt.errorMsg)
+				return
+			}
+			asrt.NoError(err)
+			asrt.Equal(tt.output, out.String())
+		})
+	}
+}
+
+func TestList_ForInSeesPushes(t *testing.T) {
+	var out bytes.Buffer
+	vm := NewVM(WithStdout(&out))
+	vm.Interpreter().Globals().Define("grow", NewNativeFunction("grow", 1, func(args []Value) (Value, error) {
+		xs := args[0].(*List)
+		if xs.Len() < 4 {
+			xs.elements = append(xs.elements, float64(xs.Len()+1))
+		}
+		return nil, nil
+	}))
+
+	_, err := vm.Eval("var xs = [1]; for x in xs { grow(xs); print x; }")
+	assert.NoError(t, err)
+	assert.Equal(t, "1\n2\n3\n4\n", out.String())
+}
+
+func TestList_MemoryLimit(t *testing.T) {
+	_, err := NewVM(WithMemoryLimit(100)).Eval("var xs = []; for x in [1, 2, 3, 4, 5, 6, 7] xs.push(x);")
+	assert.ErrorIs(t, err, ErrMemoryLimitExceeded)
+}
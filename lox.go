@@ -1,10 +1,36 @@
 package lox
 
 import (
-	"fmt"
+	"io"
 	"os"
 )
 
-func reportError(line int, where, message string) {
-	fmt.Fprintf(os.Stderr, "[line %d] Error%s: %s", line, where, message)
+// Option configures an Interpreter.
+type Option func(*Interpreter)
+
+// WithStdout sets where program output is written. It defaults to os.Stdout.
+func WithStdout(w io.Writer) Option {
+	return func(i *Interpreter) {
+		i.stdout = w
+	}
+}
+
+// WithStderr sets where diagnostics are written. It defaults to os.Stderr.
+func WithStderr(w io.Writer) Option {
+	return func(i *Interpreter) {
+		i.stderr = w
+	}
+}
+
+// WithStdin sets where program input is read from. It defaults to os.Stdin.
+func WithStdin(r io.Reader) Option {
+	return func(i *Interpreter) {
+		i.stdin = r
+	}
+}
+
+func defaultStreams(i *Interpreter) {
+	i.stdout = os.Stdout
+	i.stderr = os.Stderr
+	i.stdin = os.Stdin
 }
@@ -0,0 +1,409 @@
+package lsp
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode/utf16"
+
+	lox "github.com/mikowitz/glox"
+)
+
+// span is a scanned token together with its byte offsets in the document.
+type span struct {
+	token      lox.Token
+	start, end int
+	// declaredBy is Var, For, Catch, Fun or Class when this identifier is
+	// the name in a declaration, and EOF otherwise.
+	declaredBy lox.TokenType
+	// bound is set on the variables and declarations of a document that
+	// resolved. declaration is then the index of the span declaring the
+	// variable, or -1 for a global the document never declares.
+	bound       bool
+	declaration int
+}
+
+type document struct {
+	uri        string
+	text       string
+	lineStarts []int
+	spans      []span
+	errs       []*lox.Error
+	// resolved is set when the resolver bound the document's variables, and
+	// declarations can be found from their spans instead of by name.
+	resolved bool
+}
+
+func newDocument(uri, text string) *document {
+	d := &document{uri: uri, text: text, lineStarts: []int{0}}
+	for i, c := range text {
+		if c == '\n' {
+			d.lineStarts = append(d.lineStarts, i+1)
+		}
+	}
+
+	scanner := lox.NewScanner(text)
+	tokens, err := scanner.ScanTokens()
+	d.errs = lox.Errors(err)
+	var resolution lox.Resolution
+	if err == nil {
+		var statements []lox.Stmt
+		statements, err = lox.NewParser(tokens).ParseProgram()
+		d.errs = lox.Errors(err)
+		if err == nil {
+			resolution, err = lox.Resolve(statements)
+			d.errs = lox.Errors(err)
+			d.resolved = true
+		}
+	}
+
+	d.spans = locate(tokens)
+	for i := range d.spans {
+		d.spans[i].declaredBy = lox.EOF
+		if i == 0 || d.spans[i].token.TokenType != lox.Identifier {
+			continue
+		}
+		switch prev := d.spans[i-1].token.TokenType; prev {
+		case lox.Var, lox.For, lox.Fun, lox.Class:
+			d.spans[i].declaredBy = prev
+		case lox.LeftParen:
+			if i > 1 && d.spans[i-2].token.TokenType == lox.Catch {
+				d.spans[i].declaredBy = lox.Catch
+			}
+		}
+	}
+	if d.resolved {
+		d.resolved = d.bind(resolution)
+	}
+	return d
+}
+
+// bind records the declaration of each variable and declaration span,
+// matching the resolver's tokens to spans by their offsets. It reports false,
+// leaving spans unbound, if a token has no span.
+func (d *document) bind(resolution lox.Resolution) bool {
+	at := make(map[int]int, len(d.spans))
+	for i, sp := range d.spans {
+		at[sp.start] = i
+	}
+	spanOf := func(name lox.Token) (int, bool) {
+		i, ok := at[name.Offset]
+		return i, ok && d.spans[i].token.Lexeme == name.Lexeme
+	}
+
+	declarations := make([]int, len(resolution.Declarations))
+	for k, name := range resolution.Declarations {
+		i, ok := spanOf(name)
+		if !ok {
+			return false
+		}
+		declarations[k] = i
+	}
+	uses := make([]int, len(resolution.Uses))
+	for k, use := range resolution.Uses {
+		i, ok := spanOf(use.Name)
+		if !ok {
+			return false
+		}
+		uses[k] = i
+	}
+
+	for _, i := range declarations {
+		d.spans[i].bound, d.spans[i].declaration = true, i
+	}
+	for k, i := range uses {
+		d.spans[i].bound, d.spans[i].declaration = true, -1
+		if declaration := resolution.Uses[k].Declaration; declaration >= 0 {
+			d.spans[i].declaration = declarations[declaration]
+		}
+	}
+	return true
+}
+
+func locate(tokens []lox.Token) []span {
+	spans := make([]span, 0, len(tokens))
+	for _, token := range tokens {
+		if token.TokenType == lox.EOF {
+			break
+		}
+		spans = append(spans, span{token: token, start: token.Offset, end: token.Offset + len(token.Lexeme)})
+	}
+	return spans
+}
+
+// position converts a byte offset into an LSP position, counting characters
+// in UTF-16 code units as the protocol requires.
+func (d *document) position(offset int) Position {
+	line := sort.Search(len(d.lineStarts), func(i int) bool {
+		return d.lineStarts[i] > offset
+	}) - 1
+	prefix := d.text[d.lineStarts[line]:offset]
+	return Position{Line: line, Character: len(utf16.Encode([]rune(prefix)))}
+}
+
+func (d *document) offset(pos Position) int {
+	if pos.Line < 0 {
+		return 0
+	}
+	if pos.Line >= len(d.lineStarts) {
+		return len(d.text)
+	}
+	offset := d.lineStarts[pos.Line]
+	units := 0
+	for i, r := range d.text[offset:] {
+		if units >= pos.Character || r == '\n' {
+			return offset + i
+		}
+		units += len(utf16.Encode([]rune{r}))
+	}
+	return len(d.text)
+}
+
+func (d *document) rangeOf(sp span) Range {
+	return Range{Start: d.position(sp.start), End: d.position(sp.end)}
+}
+
+// lineRange covers the whole of a 1-based Lox source line.
+func (d *document) lineRange(line int) Range {
+	idx := min(max(line-1, 0), len(d.lineStarts)-1)
+	start := d.lineStarts[idx]
+	end := len(d.text)
+	if idx+1 < len(d.lineStarts) {
+		end = d.lineStarts[idx+1] - 1
+	}
+	return Range{Start: d.position(start), End: d.position(end)}
+}
+
+func (d *document) spanAt(pos Position) (span, bool) {
+	offset := d.offset(pos)
+	for _, sp := range d.spans {
+		if sp.start <= offset && offset < sp.end {
+			return sp, true
+		}
+	}
+	return span{}, false
+}
+
+// declarationOf finds the declaration of the variable at sp. In a document
+// that resolved, that's the declaration the resolver bound it to. Otherwise,
+// such as while the document has syntax errors, the best guess is the
+// nearest declaration of the same name before sp, or failing that the first
+// one after it.
+func (d *document) declarationOf(sp span) (span, bool) {
+	if !d.resolved {
+		return d.declaration(sp)
+	}
+	if !sp.bound || sp.declaration < 0 {
+		return span{}, false
+	}
+	return d.spans[sp.declaration], true
+}
+
+func (d *document) declaration(use span) (span, bool) {
+	var found span
+	ok := false
+	for _, sp := range d.spans {
+		if sp.declaredBy == lox.EOF || sp.token.Lexeme != use.token.Lexeme {
+			continue
+		}
+		if ok && sp.start > use.start {
+			break
+		}
+		found, ok = sp, true
+	}
+	return found, ok
+}
+
+func (d *document) diagnostics() []Diagnostic {
+	diagnostics := []Diagnostic{}
+	for _, err := range d.errs {
+		diagnostics = append(diagnostics, Diagnostic{
+			Range:    d.lineRange(err.Line),
+			Severity: severityError,
+			Source:   "glox",
+			Message:  err.Error(),
+		})
+	}
+	return diagnostics
+}
+
+func (d *document) hover(pos Position) *Hover {
+	sp, ok := d.spanAt(pos)
+	if !ok {
+		return nil
+	}
+
+	var text string
+	switch sp.token.TokenType {
+	case lox.Number:
+		text = fmt.Sprintf("(number) %v", sp.token.Object)
+	case lox.String:
+		text = fmt.Sprintf("(string) %s", sp.token.Lexeme)
+	case lox.True, lox.False:
+		text = fmt.Sprintf("(boolean) %s", sp.token.Lexeme)
+	case lox.Nil:
+		text = "(nil) nil"
+	case lox.Identifier:
+		decl, ok := d.declarationOf(sp)
+		if !ok {
+			return nil
+		}
+		keyword := strings.ToLower(decl.declaredBy.String())
+		text = fmt.Sprintf("%s %s (line %d)", keyword, decl.token.Lexeme, decl.token.Line)
+	default:
+		return nil
+	}
+
+	return &Hover{
+		Contents: MarkupContent{Kind: "plaintext", Value: text},
+		Range:    d.rangeOf(sp),
+	}
+}
+
+func (d *document) definition(pos Position) *Location {
+	sp, ok := d.spanAt(pos)
+	if !ok || sp.token.TokenType != lox.Identifier {
+		return nil
+	}
+	decl, ok := d.declarationOf(sp)
+	if !ok {
+		return nil
+	}
+	return &Location{URI: d.uri, Range: d.rangeOf(decl)}
+}
+
+func (d *document) references(pos Position, includeDeclaration bool) []Location {
+	locations := []Location{}
+	sp, ok := d.spanAt(pos)
+	if !ok || sp.token.TokenType != lox.Identifier {
+		return locations
+	}
+	for _, other := range d.spans {
+		if other.token.TokenType != lox.Identifier || other.token.Lexeme != sp.token.Lexeme {
+			continue
+		}
+		if d.resolved && (!other.bound || !sp.bound || other.declaration != sp.declaration) {
+			// Another variable of the same name, or a property.
+			continue
+		}
+		if other.declaredBy != lox.EOF && !includeDeclaration {
+			continue
+		}
+		locations = append(locations, Location{URI: d.uri, Range: d.rangeOf(other)})
+	}
+	return locations
+}
+
+func (d *document) symbols() []DocumentSymbol {
+	symbols := []DocumentSymbol{}
+	for _, sp := range d.spans {
+		var kind int
+		switch sp.declaredBy {
+		case lox.Fun:
+			kind = symbolKindFunction
+		case lox.Class:
+			kind = symbolKindClass
+		default:
+			continue
+		}
+		r := d.rangeOf(sp)
+		symbols = append(symbols, DocumentSymbol{
+			Name:           sp.token.Lexeme,
+			Kind:           kind,
+			Range:          r,
+			SelectionRange: r,
+		})
+	}
+	return symbols
+}
+
+// semanticTokenTypes is the legend advertised in the server capabilities;
+// semanticTokens refers to entries by index.
+var semanticTokenTypes = []string{"keyword", "string", "number", "operator", "variable", "function", "class"}
+
+const (
+	semanticKeyword = iota
+	semanticString
+	semanticNumber
+	semanticOperator
+	semanticVariable
+	semanticFunction
+	semanticClass
+)
+
+func (d *document) semanticTokens() SemanticTokens {
+	data := []int{}
+	prev := Position{}
+	for _, sp := range d.spans {
+		tokenType, ok := d.semanticType(sp)
+		if !ok {
+			continue
+		}
+		r := d.rangeOf(sp)
+		if r.Start.Line != r.End.Line {
+			// Multi-line strings cannot be expressed without the
+			// multilineTokenSupport client capability.
+			continue
+		}
+
+		deltaStart := r.Start.Character
+		if r.Start.Line == prev.Line {
+			deltaStart -= prev.Character
+		}
+		data = append(data,
+			r.Start.Line-prev.Line,
+			deltaStart,
+			r.End.Character-r.Start.Character,
+			tokenType,
+			0,
+		)
+		prev = r.Start
+	}
+	return SemanticTokens{Data: data}
+}
+
+// semanticKeywords and semanticOperators list the token types highlighted
+// as keywords and operators. Punctuation such as parentheses, commas and
+// dots isn't highlighted.
+var (
+	semanticKeywords = map[lox.TokenType]bool{
+		lox.And: true, lox.Catch: true, lox.Class: true, lox.Else: true, lox.False: true,
+		lox.Finally: true, lox.Fun: true, lox.For: true, lox.If: true, lox.In: true,
+		lox.Nil: true, lox.Or: true, lox.Print: true, lox.Return: true, lox.Super: true,
+		lox.This: true, lox.Throw: true, lox.True: true, lox.Try: true, lox.Var: true,
+		lox.While: true,
+	}
+	semanticOperators = map[lox.TokenType]bool{
+		lox.Minus: true, lox.Plus: true, lox.Slash: true, lox.Star: true,
+		lox.Bang: true, lox.BangEqual: true, lox.Equal: true, lox.EqualEqual: true,
+		lox.Greater: true, lox.GreaterEqual: true, lox.Less: true, lox.LessEqual: true,
+	}
+)
+
+func (d *document) semanticType(sp span) (int, bool) {
+	switch tt := sp.token.TokenType; {
+	case tt == lox.String:
+		return semanticString, true
+	case tt == lox.Number:
+		return semanticNumber, true
+	case tt == lox.Identifier:
+		decl, ok := sp, sp.declaredBy != lox.EOF
+		if !ok {
+			decl, ok = d.declarationOf(sp)
+		}
+		if ok {
+			switch decl.declaredBy {
+			case lox.Fun:
+				return semanticFunction, true
+			case lox.Class:
+				return semanticClass, true
+			}
+		}
+		return semanticVariable, true
+	case semanticKeywords[tt]:
+		return semanticKeyword, true
+	case semanticOperators[tt]:
+		return semanticOperator, true
+	}
+	return 0, false
+}
@@ -0,0 +1,219 @@
+package lsp
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/mikowitz/glox/internal/framing"
+)
+
+// Server speaks the Language Server Protocol over a pair of streams, usually
+// the process's stdin and stdout.
+type Server struct {
+	in   *bufio.Reader
+	out  io.Writer
+	docs map[string]*document
+}
+
+func NewServer(in io.Reader, out io.Writer) *Server {
+	return &Server{
+		in:   bufio.NewReader(in),
+		out:  out,
+		docs: map[string]*document{},
+	}
+}
+
+// Run serves requests until the client sends exit or closes the input stream.
+func (s *Server) Run() error {
+	for {
+		body, err := framing.Read(s.in)
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		var msg message
+		if err := json.Unmarshal(body, &msg); err != nil {
+			if err := s.replyError(nil, codeParseError, err.Error()); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if msg.Method == "exit" {
+			return nil
+		}
+		if err := s.handle(msg); err != nil {
+			return err
+		}
+	}
+}
+
+func (s *Server) handle(msg message) error {
+	result, err := s.dispatch(msg)
+	if msg.ID == nil {
+		// Notifications never get a response, even when they fail.
+		return nil
+	}
+
+	var rpcErr *responseError
+	if errors.As(err, &rpcErr) {
+		return s.replyError(msg.ID, rpcErr.Code, rpcErr.Message)
+	}
+	if err != nil {
+		return err
+	}
+	return framing.Write(s.out, map[string]any{
+		"jsonrpc": "2.0",
+		"id":      msg.ID,
+		"result":  result,
+	})
+}
+
+func (s *Server) dispatch(msg message) (any, error) {
+	switch msg.Method {
+	case "initialize":
+		return s.initialize(), nil
+	case "initialized", "$/cancelRequest", "$/setTrace":
+		return nil, nil
+	case "shutdown":
+		return nil, nil
+	case "textDocument/didOpen":
+		var params didOpenParams
+		if err := decodeParams(msg, &params); err != nil {
+			return nil, err
+		}
+		return nil, s.update(params.TextDocument.URI, params.TextDocument.Text)
+	case "textDocument/didChange":
+		var params didChangeParams
+		if err := decodeParams(msg, &params); err != nil {
+			return nil, err
+		}
+		if len(params.ContentChanges) == 0 {
+			return nil, nil
+		}
+		text := params.ContentChanges[len(params.ContentChanges)-1].Text
+		return nil, s.update(params.TextDocument.URI, text)
+	case "textDocument/didClose":
+		var params didCloseParams
+		if err := decodeParams(msg, &params); err != nil {
+			return nil, err
+		}
+		delete(s.docs, params.TextDocument.URI)
+		return nil, s.publishDiagnostics(params.TextDocument.URI, []Diagnostic{})
+	case "textDocument/hover":
+		return withPosition(s, msg, func(d *document, p positionParams) any {
+			if h := d.hover(p.Position); h != nil {
+				return h
+			}
+			return nil
+		})
+	case "textDocument/definition":
+		return withPosition(s, msg, func(d *document, p positionParams) any {
+			if loc := d.definition(p.Position); loc != nil {
+				return loc
+			}
+			return nil
+		})
+	case "textDocument/references":
+		return withPosition(s, msg, func(d *document, p positionParams) any {
+			return d.references(p.Position, p.Context.IncludeDeclaration)
+		})
+	case "textDocument/documentSymbol":
+		doc, err := s.document(msg)
+		if err != nil {
+			return nil, err
+		}
+		return doc.symbols(), nil
+	case "textDocument/semanticTokens/full":
+		doc, err := s.document(msg)
+		if err != nil {
+			return nil, err
+		}
+		return doc.semanticTokens(), nil
+	}
+
+	return nil, &responseError{Code: codeMethodNotFound, Message: fmt.Sprintf("method not found: %s", msg.Method)}
+}
+
+func (s *Server) initialize() any {
+	return map[string]any{
+		"capabilities": map[string]any{
+			"textDocumentSync":       1, // full document sync
+			"hoverProvider":          true,
+			"definitionProvider":     true,
+			"referencesProvider":     true,
+			"documentSymbolProvider": true,
+			"semanticTokensProvider": map[string]any{
+				"legend": map[string]any{
+					"tokenTypes":     semanticTokenTypes,
+					"tokenModifiers": []string{},
+				},
+				"full": true,
+			},
+		},
+		"serverInfo": map[string]any{"name": "glox"},
+	}
+}
+
+func (s *Server) update(uri, text string) error {
+	doc := newDocument(uri, text)
+	s.docs[uri] = doc
+	return s.publishDiagnostics(uri, doc.diagnostics())
+}
+
+func (s *Server) publishDiagnostics(uri string, diagnostics []Diagnostic) error {
+	return framing.Write(s.out, map[string]any{
+		"jsonrpc": "2.0",
+		"method":  "textDocument/publishDiagnostics",
+		"params":  PublishDiagnosticsParams{URI: uri, Diagnostics: diagnostics},
+	})
+}
+
+func (s *Server) document(msg message) (*document, error) {
+	var params textDocumentParams
+	if err := decodeParams(msg, &params); err != nil {
+		return nil, err
+	}
+	doc, ok := s.docs[params.TextDocument.URI]
+	if !ok {
+		return nil, &responseError{Code: codeInvalidParams, Message: fmt.Sprintf("unknown document: %s", params.TextDocument.URI)}
+	}
+	return doc, nil
+}
+
+func withPosition(s *Server, msg message, fn func(*document, positionParams) any) (any, error) {
+	var params positionParams
+	if err := decodeParams(msg, &params); err != nil {
+		return nil, err
+	}
+	doc, err := s.document(msg)
+	if err != nil {
+		return nil, err
+	}
+	return fn(doc, params), nil
+}
+
+func (s *Server) replyError(id json.RawMessage, code int, msg string) error {
+	return framing.Write(s.out, map[string]any{
+		"jsonrpc": "2.0",
+		"id":      id,
+		"error":   responseError{Code: code, Message: msg},
+	})
+}
+
+func decodeParams(msg message, v any) error {
+	if err := json.Unmarshal(msg.Params, v); err != nil {
+		return &responseError{Code: codeInvalidParams, Message: err.Error()}
+	}
+	return nil
+}
+
+func (e *responseError) Error() string {
+	return e.Message
+}
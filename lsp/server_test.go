@@ -0,0 +1,405 @@
+// ABOUTME: Tests for the language server, driven by a scripted in-process client
+// ABOUTME: Covers diagnostics, hover, scope-aware navigation, symbols and semantic tokens
+package lsp
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"testing"
+
+	"github.com/mikowitz/glox/internal/framing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testClient drives a Server over in-memory pipes, recording any
+// notifications that arrive while it waits for responses. Server output is
+// drained on a separate goroutine so that notifications never block writes.
+type testClient struct {
+	t             *testing.T
+	w             io.WriteCloser
+	incoming      chan []byte
+	nextID        int
+	notifications []message
+	done          chan error
+}
+
+func newTestClient(t *testing.T) *testClient {
+	clientR, serverW := io.Pipe()
+	serverR, clientW := io.Pipe()
+
+	c := &testClient{
+		t:        t,
+		w:        clientW,
+		incoming: make(chan []byte, 64),
+		done:     make(chan error, 1),
+	}
+	go func() {
+		r := bufio.NewReader(clientR)
+		for {
+			body, err := framing.Read(r)
+			if err != nil {
+				close(c.incoming)
+				return
+			}
+			c.incoming <- body
+		}
+	}()
+	go func() {
+		c.done <- NewServer(serverR, serverW).Run()
+		serverW.Close()
+	}()
+	t.Cleanup(func() { clientW.Close() })
+
+	c.call("initialize", map[string]any{})
+	c.notify("initialized", map[string]any{})
+	return c
+}
+
+func (c *testClient) notify(method string, params any) {
+	raw, err := json.Marshal(params)
+	require.NoError(c.t, err)
+	require.NoError(c.t, framing.Write(c.w, message{JSONRPC: "2.0", Method: method, Params: raw}))
+}
+
+func (c *testClient) call(method string, params any) json.RawMessage {
+	c.nextID++
+	id, _ := json.Marshal(c.nextID)
+	raw, err := json.Marshal(params)
+	require.NoError(c.t, err)
+	require.NoError(c.t, framing.Write(c.w, message{JSONRPC: "2.0", ID: id, Method: method, Params: raw}))
+
+	for body := range c.incoming {
+		var resp struct {
+			ID     json.RawMessage `json:"id"`
+			Method string          `json:"method"`
+			Params json.RawMessage `json:"params"`
+			Result json.RawMessage `json:"result"`
+			Error  *responseError  `json:"error"`
+		}
+		require.NoError(c.t, json.Unmarshal(body, &resp))
+		if resp.Method != "" {
+			c.notifications = append(c.notifications, message{Method: resp.Method, Params: resp.Params})
+			continue
+		}
+		require.Equal(c.t, string(id), string(resp.ID))
+		require.Nil(c.t, resp.Error)
+		return resp.Result
+	}
+	c.t.Fatal("server closed the connection")
+	return nil
+}
+
+func (c *testClient) open(uri, text string) {
+	c.notify("textDocument/didOpen", map[string]any{
+		"textDocument": map[string]any{"uri": uri, "languageId": "lox", "version": 1, "text": text},
+	})
+}
+
+// diagnostics flushes pending notifications with a round trip and returns
+// the most recent diagnostics published for uri.
+func (c *testClient) diagnostics(uri string) []Diagnostic {
+	c.call("shutdown", nil)
+	var latest []Diagnostic
+	for _, n := range c.notifications {
+		if n.Method != "textDocument/publishDiagnostics" {
+			continue
+		}
+		var params PublishDiagnosticsParams
+		require.NoError(c.t, json.Unmarshal(n.Params, &params))
+		if params.URI == uri {
+			latest = params.Diagnostics
+		}
+	}
+	return latest
+}
+
+func positionRequest(uri string, line, character int) map[string]any {
+	return map[string]any{
+		"textDocument": map[string]any{"uri": uri},
+		"position":     map[string]any{"line": line, "character": character},
+		"context":      map[string]any{"includeDeclaration": true},
+	}
+}
+
+func TestServer_Diagnostics(t *testing.T) {
+	tests := []struct {
+		name     string
+		source   string
+		expected []Diagnostic
+	}{
+		{
+			name:     "valid expression",
+			source:   "1 + 2",
+			expected: []Diagnostic{},
+		},
+		{
+			name:   "scanner error",
+			source: "1 +\n@",
+			expected: []Diagnostic{
+				{
+					Range:    Range{Start: Position{Line: 1, Character: 0}, End: Position{Line: 1, Character: 1}},
+					Severity: severityError,
+					Source:   "glox",
+					Message:  "[line 2] syntax error: unexpected character",
+				},
+			},
+		},
+		{
+			name:   "parser error",
+			source: "(1 + 2",
+			expected: []Diagnostic{
+				{
+					Range:    Range{Start: Position{Line: 0, Character: 0}, End: Position{Line: 0, Character: 6}},
+					Severity: severityError,
+					Source:   "glox",
+					Message:  "[line 1] syntax error at end: expect ')' after expression",
+				},
+			},
+		},
+		{
+			name:   "resolver error",
+			source: "var a = 1;\n{ var a = a; }",
+			expected: []Diagnostic{
+				{
+					Range:    Range{Start: Position{Line: 1, Character: 0}, End: Position{Line: 1, Character: 14}},
+					Severity: severityError,
+					Source:   "glox",
+					Message:  "[line 2] syntax error at 'a': can't read local variable in its own initializer",
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			asrt := assert.New(t)
+			client := newTestClient(t)
+			client.open("file:///test.lox", tt.source)
+
+			asrt.Equal(tt.expected, client.diagnostics("file:///test.lox"))
+		})
+	}
+}
+
+func TestServer_DiagnosticsOnChange(t *testing.T) {
+	asrt := assert.New(t)
+	client := newTestClient(t)
+	client.open("file:///test.lox", "(1")
+	asrt.Len(client.diagnostics("file:///test.lox"), 1)
+
+	client.notify("textDocument/didChange", map[string]any{
+		"textDocument":   map[string]any{"uri": "file:///test.lox", "version": 2},
+		"contentChanges": []map[string]any{{"text": "(1)"}},
+	})
+	asrt.Empty(client.diagnostics("file:///test.lox"))
+}
+
+func TestServer_Hover(t *testing.T) {
+	source := "var answer = 42;\n\"hi\" == answer // nil\nnil != true"
+	tests := []struct {
+		name      string
+		line, col int
+		expected  string
+	}{
+		{name: "number literal", line: 0, col: 14, expected: "(number) 42"},
+		{name: "string literal", line: 1, col: 1, expected: `(string) "hi"`},
+		{name: "nil literal", line: 2, col: 0, expected: "(nil) nil"},
+		{name: "boolean literal", line: 2, col: 8, expected: "(boolean) true"},
+		{name: "variable reference", line: 1, col: 10, expected: "var answer (line 1)"},
+		{name: "operator", line: 1, col: 6, expected: ""},
+		{name: "comment", line: 1, col: 20, expected: ""},
+	}
+
+	client := newTestClient(t)
+	client.open("file:///test.lox", source)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			asrt := assert.New(t)
+			raw := client.call("textDocument/hover", positionRequest("file:///test.lox", tt.line, tt.col))
+
+			var hover *Hover
+			asrt.NoError(json.Unmarshal(raw, &hover))
+			if tt.expected == "" {
+				asrt.Nil(hover)
+				return
+			}
+			asrt.NotNil(hover)
+			asrt.Equal(tt.expected, hover.Contents.Value)
+		})
+	}
+}
+
+func TestServer_HoverAfterScanError(t *testing.T) {
+	asrt := assert.New(t)
+	client := newTestClient(t)
+	client.open("file:///test.lox", "var x = 1;\n@# \"a\nb\" x")
+
+	raw := client.call("textDocument/hover", positionRequest("file:///test.lox", 2, 3))
+	var hover *Hover
+	asrt.NoError(json.Unmarshal(raw, &hover))
+	asrt.NotNil(hover)
+	asrt.Equal("var x (line 1)", hover.Contents.Value)
+	asrt.Equal(Range{Start: Position{Line: 2, Character: 3}, End: Position{Line: 2, Character: 4}}, hover.Range)
+}
+
+func TestServer_DefinitionAndReferences(t *testing.T) {
+	asrt := assert.New(t)
+	source := "fun area(r) {}\narea(2) + area(3)"
+	client := newTestClient(t)
+	client.open("file:///test.lox", source)
+
+	raw := client.call("textDocument/definition", positionRequest("file:///test.lox", 1, 11))
+	var loc Location
+	asrt.NoError(json.Unmarshal(raw, &loc))
+	asrt.Equal(Location{
+		URI:   "file:///test.lox",
+		Range: Range{Start: Position{Line: 0, Character: 4}, End: Position{Line: 0, Character: 8}},
+	}, loc)
+
+	raw = client.call("textDocument/references", positionRequest("file:///test.lox", 1, 0))
+	var refs []Location
+	asrt.NoError(json.Unmarshal(raw, &refs))
+	asrt.Len(refs, 3)
+	asrt.Equal(Position{Line: 1, Character: 10}, refs[2].Range.Start)
+}
+
+func TestServer_DefinitionFollowsScopes(t *testing.T) {
+	source := "var a = 1;\n{ var a = 2; print a; } print a;\nfor x in [a] { print x + len([]); }\ntry {} catch (a) { print a.len; }"
+	declaredAt := func(line, character int) *Range {
+		return &Range{Start: Position{Line: line, Character: character}, End: Position{Line: line, Character: character + 1}}
+	}
+	tests := []struct {
+		name      string
+		line, col int
+		expected  *Range
+	}{
+		{name: "global", line: 1, col: 30, expected: declaredAt(0, 4)},
+		{name: "shadowing local on the same line", line: 1, col: 19, expected: declaredAt(1, 6)},
+		{name: "declaration", line: 1, col: 6, expected: declaredAt(1, 6)},
+		{name: "loop variable", line: 2, col: 21, expected: declaredAt(2, 4)},
+		{name: "global in a loop", line: 2, col: 10, expected: declaredAt(0, 4)},
+		{name: "catch variable", line: 3, col: 25, expected: declaredAt(3, 14)},
+		{name: "built-in", line: 2, col: 26},
+		{name: "property", line: 3, col: 28},
+	}
+
+	client := newTestClient(t)
+	client.open("file:///test.lox", source)
+	assert.Empty(t, client.diagnostics("file:///test.lox"))
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			asrt := assert.New(t)
+			raw := client.call("textDocument/definition", positionRequest("file:///test.lox", tt.line, tt.col))
+			var loc *Location
+			asrt.NoError(json.Unmarshal(raw, &loc))
+			if tt.expected == nil {
+				asrt.Nil(loc)
+				return
+			}
+			asrt.NotNil(loc)
+			asrt.Equal(*tt.expected, loc.Range)
+		})
+	}
+
+	raw := client.call("textDocument/references", positionRequest("file:///test.lox", 0, 4))
+	var refs []Location
+	assert.NoError(t, json.Unmarshal(raw, &refs))
+	var starts []Position
+	for _, ref := range refs {
+		starts = append(starts, ref.Range.Start)
+	}
+	assert.Equal(t, []Position{{Line: 0, Character: 4}, {Line: 1, Character: 30}, {Line: 2, Character: 10}}, starts, "only the global, not the locals of the same name")
+
+	raw = client.call("textDocument/hover", positionRequest("file:///test.lox", 2, 21))
+	var hover *Hover
+	assert.NoError(t, json.Unmarshal(raw, &hover))
+	assert.Equal(t, "for x (line 3)", hover.Contents.Value)
+}
+
+func TestServer_DocumentSymbols(t *testing.T) {
+	asrt := assert.New(t)
+	source := "class Shape {}\nfun area(r) {}\nvar x = 1;"
+	client := newTestClient(t)
+	client.open("file:///test.lox", source)
+
+	raw := client.call("textDocument/documentSymbol", map[string]any{
+		"textDocument": map[string]any{"uri": "file:///test.lox"},
+	})
+	var symbols []DocumentSymbol
+	asrt.NoError(json.Unmarshal(raw, &symbols))
+	asrt.Len(symbols, 2)
+	asrt.Equal("Shape", symbols[0].Name)
+	asrt.Equal(symbolKindClass, symbols[0].Kind)
+	asrt.Equal("area", symbols[1].Name)
+	asrt.Equal(symbolKindFunction, symbols[1].Kind)
+}
+
+func TestServer_SemanticTokens(t *testing.T) {
+	asrt := assert.New(t)
+	source := "!true\n  \"a\" + 12"
+	client := newTestClient(t)
+	client.open("file:///test.lox", source)
+
+	raw := client.call("textDocument/semanticTokens/full", map[string]any{
+		"textDocument": map[string]any{"uri": "file:///test.lox"},
+	})
+	var tokens SemanticTokens
+	asrt.NoError(json.Unmarshal(raw, &tokens))
+	asrt.Equal([]int{
+		0, 0, 1, semanticOperator, 0,
+		0, 1, 4, semanticKeyword, 0,
+		1, 2, 3, semanticString, 0,
+		0, 4, 1, semanticOperator, 0,
+		0, 2, 2, semanticNumber, 0,
+	}, tokens.Data)
+}
+
+func TestServer_SemanticTokensShadowing(t *testing.T) {
+	asrt := assert.New(t)
+	source := "class C {}\n{ var C = 1; print C; }"
+	client := newTestClient(t)
+	client.open("file:///test.lox", source)
+
+	raw := client.call("textDocument/semanticTokens/full", map[string]any{
+		"textDocument": map[string]any{"uri": "file:///test.lox"},
+	})
+	var tokens SemanticTokens
+	asrt.NoError(json.Unmarshal(raw, &tokens))
+	asrt.Equal([]int{
+		0, 0, 5, semanticKeyword, 0,
+		0, 6, 1, semanticClass, 0,
+		1, 2, 3, semanticKeyword, 0,
+		0, 4, 1, semanticVariable, 0,
+		0, 2, 1, semanticOperator, 0,
+		0, 2, 1, semanticNumber, 0,
+		0, 3, 5, semanticKeyword, 0,
+		0, 6, 1, semanticVariable, 0,
+	}, tokens.Data, "the local C is a variable, not the class")
+}
+
+func TestServer_UnknownMethod(t *testing.T) {
+	asrt := assert.New(t)
+	client := newTestClient(t)
+
+	id, _ := json.Marshal(99)
+	asrt.NoError(framing.Write(client.w, message{JSONRPC: "2.0", ID: id, Method: "workspace/unknown"}))
+	body := <-client.incoming
+
+	var resp struct {
+		Error *responseError `json:"error"`
+	}
+	asrt.NoError(json.Unmarshal(body, &resp))
+	asrt.NotNil(resp.Error)
+	asrt.Equal(codeMethodNotFound, resp.Error.Code)
+}
+
+func TestServer_Exit(t *testing.T) {
+	client := newTestClient(t)
+	client.call("shutdown", nil)
+	client.notify("exit", nil)
+	assert.NoError(t, <-client.done)
+}
@@ -0,0 +1,122 @@
+package lox
+
+import (
+	"fmt"
+	"math"
+	"slices"
+)
+
+// mapEntrySize is the number of bytes charged against the memory limit for
+// each entry stored in a map.
+const mapEntrySize = 32
+
+// Map is a mutable collection of key/value pairs that remembers the order
+// keys were first added in. Like lists, maps are reference values.
+//
+// Keys are compared the same way == compares values, so only nil, booleans,
+// numbers and strings may be used as keys.
+type Map struct {
+	keys   []Value
+	values map[Value]Value
+}
+
+func NewMap() *Map {
+	return &Map{values: map[Value]Value{}}
+}
+
+func (m *Map) Len() int {
+	return len(m.keys)
+}
+
+// Keys returns the map's keys in insertion order.
+func (m *Map) Keys() []Value {
+	return slices.Clone(m.keys)
+}
+
+// Get returns the value stored under key.
+func (m *Map) Get(key Value) (Value, bool) {
+	value, ok := m.values[key]
+	return value, ok
+}
+
+// Set stores value under key, reporting whether key is new. Keys that are
+// already present keep their original position.
+func (m *Map) Set(key, value Value) bool {
+	_, exists := m.values[key]
+	if !exists {
+		m.keys = append(m.keys, key)
+	}
+	m.values[key] = value
+	return !exists
+}
+
+// Delete removes key, returning the value it held.
+func (m *Map) Delete(key Value) (Value, bool) {
+	value, ok := m.values[key]
+	if !ok {
+		return nil, false
+	}
+	delete(m.values, key)
+	m.keys = slices.DeleteFunc(m.keys, func(k Value) bool { return k == key })
+	return value, true
+}
+
+func (m *Map) String() string {
+	return Stringify(m)
+}
+
+// checkKey reports whether key can be stored in a map.
+func checkKey(key Value) error {
+	switch k := key.(type) {
+	case nil, bool, string:
+		return nil
+	case float64:
+		// NaN is never equal to itself, so it could never be looked up again.
+		if math.IsNaN(k) {
+			return fmt.Errorf("map key can't be NaN")
+		}
+		return nil
+	}
+	return fmt.Errorf("map keys must be nil, booleans, numbers or strings, got %s", TypeOf(key))
+}
+
+// method returns the built-in method called name, bound to m.
+func (m *Map) method(name string) (*NativeFunction, bool) {
+	switch name {
+	case "keys":
+		return native("keys", 0, func(i *Interpreter, _ []Value) (Value, error) {
+			i.Allocate(len(m.keys) * listElementSize)
+			return NewList(m.Keys()...), nil
+		}), true
+	case "values":
+		return native("values", 0, func(i *Interpreter, _ []Value) (Value, error) {
+			i.Allocate(len(m.keys) * listElementSize)
+			values := make([]Value, 0, len(m.keys))
+			for _, key := range m.keys {
+				values = append(values, m.values[key])
+			}
+			return NewList(values...), nil
+		}), true
+	case "has":
+		return native("has", 1, func(_ *Interpreter, args []Value) (Value, error) {
+			if err := checkKey(args[0]); err != nil {
+				return nil, err
+			}
+			_, ok := m.values[args[0]]
+			return ok, nil
+		}), true
+	case "remove":
+		return native("remove", 1, func(_ *Interpreter, args []Value) (Value, error) {
+			if err := checkKey(args[0]); err != nil {
+				return nil, err
+			}
+			value, _ := m.Delete(args[0])
+			return value, nil
+		}), true
+	case "len":
+		return native("len", 0, func(_ *Interpreter, _ []Value) (Value, error) {
+			return float64(len(m.keys)), nil
+		}), true
+	}
+	return nil, false
+}
@@ -0,0 +1,109 @@
+// ABOUTME: Tests for map values: literals, key access, methods and iteration
+// ABOUTME: Runs Lox programs through the VM and checks results and runtime errors
+package lox
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMap(t *testing.T) {
+	tests := []struct {
+		name     string
+		source   string
+		expected string
+		errorMsg string
+	}{
+		// Literals and access
+		{name: "literal", source: `{"a": 1, "b": [2]}`, expected: `{"a": 1, "b": [2]}`},
+		{name: "empty literal", source: "var m = {}; m", expected: "{}"},
+		{name: "mixed key types", source: `{nil: 1, true: 2, 3: "c", "d": nil}`, expected: `{nil: 1, true: 2, 3: "c", "d": nil}`},
+		{name: "duplicate keys keep the first position", source: `{"a": 1, "b": 2, "a": 3}`, expected: `{"a": 3, "b": 2}`},
+		{name: "get", source: `{"a": 1, "b": 2}["b"]`, expected: "2"},
+		{name: "get missing", source: `{"a": 1}["z"]`, expected: "nil"},
+		{name: "numeric keys compare like ==", source: `{1: "one"}[2 - 1]`, expected: "one"},
+		{name: "zero and negative zero are the same key", source: "{0: 1}[-0]", expected: "1"},
+		{name: "set", source: `var m = {}; m["x"] = 1; m["y"] = 2; m["x"] = 3; m`, expected: `{"x": 3, "y": 2}`},
+		{name: "containing itself", source: `var m = {"a": 1}; m["self"] = m; m["list"] = [m]; m`, expected: `{"a": 1, "self": {...}, "list": [{...}]}`},
+		{name: "list and map containing each other", source: `var m = {}; var xs = [m]; m["xs"] = xs; xs`, expected: `[{"xs": [...]}]`},
+		{name: "maps are shared", source: `var m = {}; var n = m; n[1] = 2; m`, expected: "{1: 2}"},
+		{name: "list key", source: "var m = {};\nm[[1]] = 1", errorMsg: "[line 2] runtime error: map keys must be nil, booleans, numbers or strings, got list"},
+		{name: "map key in literal", source: `var m = {{}: 1};`, errorMsg: "[line 1] runtime error: map keys must be nil, booleans, numbers or strings, got map"},
+		{name: "function key", source: `{"a": 1}[len]`, errorMsg: "[line 1] runtime error: map keys must be nil, booleans, numbers or strings, got function"},
+		{name: "NaN key", source: `var m = {}; m[sqrt(-1)] = 1`, errorMsg: "[line 1] runtime error: map key can't be NaN"},
+
+		// Methods
+		{name: "keys", source: `{"b": 1, "a": 2}.keys()`, expected: `["b", "a"]`},
+		{name: "values", source: `{"b": 1, "a": 2}.values()`, expected: "[1, 2]"},
+		{name: "has", source: `var m = {"a": nil}; m.has("a")`, expected: "true"},
+		{name: "has missing", source: `{"a": 1}.has("b")`, expected: "false"},
+		{name: "remove", source: `var m = {"a": 1, "b": 2, "c": 3}; m.remove("b") + m.len()`, expected: "4"},
+		{name: "remove keeps order", source: `var m = {"a": 1, "b": 2, "c": 3}; m.remove("a"); m["a"] = 4; m`, expected: `{"b": 2, "c": 3, "a": 4}`},
+		{name: "remove missing", source: `({}).remove("a")`, expected: "nil"},
+		{name: "has: bad key", source: `({}).has([])`, errorMsg: "[line 1] runtime error: map keys must be nil, booleans, numbers or strings, got list"},
+		{name: "len builtin", source: `len({"a": 1, "b": 2})`, expected: "2"},
+		{name: "type", source: "type({})", expected: "map"},
+		{name: "unknown method", source: "({}).push", errorMsg: "[line 1] runtime error: undefined property 'push'"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			asrt := assert.New(t)
+			result, err := NewVM().Eval(tt.source)
+
+			if tt.errorMsg != "" {
+				asrt.ErrorIs(err, ErrLoxRuntime)
+				asrt.EqualError(err, tt.errorMsg)
+				return
+			}
+			asrt.NoError(err)
+			asrt.Equal(tt.expected, Stringify(result))
+		})
+	}
+}
+
+func TestMap_ForIn(t *testing.T) {
+	var out bytes.Buffer
+	_, err := NewVM(WithStdout(&out)).Eval(`
+var m = {"z": 1, "a": 2};
+m["m"] = 3;
+for key in m print key + "=" + str(m[key]);
+`)
+	assert.NoError(t, err)
+	assert.Equal(t, "z=1\na=2\nm=3\n", out.String())
+}
+
+func TestMap_ForInModified(t *testing.T) {
+	tests := []struct {
+		name     string
+		body     string
+		expected string
+	}{
+		{name: "removing the current key", body: `m.remove(key);`, expected: "a b c d "},
+		{name: "removing later keys", body: `if (key == "a") { m.remove("b"); m.remove("c"); }`, expected: "a d "},
+		{name: "removing earlier keys", body: `if (key == "c") { m.remove("a"); m.remove("b"); }`, expected: "a b c d "},
+		{name: "adding keys", body: `m[key + key] = 1;`, expected: "a b c d "},
+		{name: "removing and adding back", body: `if (key == "a") { m.remove("b"); m["b"] = 2; }`, expected: "a b c d "},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			_, err := NewVM(WithStdout(&out)).Eval(`
+var m = {"a": 1, "b": 1, "c": 1, "d": 1};
+var seen = "";
+for key in m { seen = seen + key + " "; ` + tt.body + ` }
+print seen;
+`)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected+"\n", out.String())
+		})
+	}
+}
+
+func TestMap_MemoryLimit(t *testing.T) {
+	_, err := NewVM(WithMemoryLimit(100)).Eval("var m = {}; for x in [1, 2, 3, 4] m[x] = x;")
+	assert.ErrorIs(t, err, ErrMemoryLimitExceeded)
+}
@@ -0,0 +1,230 @@
+package lox
+
+import (
+	"errors"
+)
+
+// Optimizer simplifies parsed programs before they run. It is a pipeline of
+// passes, each a Rewrite over the whole tree, that remove redundant groups,
+// fold constant subexpressions, cancel double negations and drop branches of
+// if statements that can never run.
+//
+// Every node a pass puts in place of another is given that node's Origin, so
+// spans and debugger locations still lead back to the source as written.
+// Optimization never changes what a program does. In particular, a constant
+// expression that fails, such as -"a", is left in place so that it still
+// fails at runtime on its own line, unless WithConstantErrors is given.
+type Optimizer struct {
+	constantErrors bool
+	errs           []error
+}
+
+// OptimizerOption configures an Optimizer.
+type OptimizerOption func(*Optimizer)
+
+// WithConstantErrors makes the optimizer report constant expressions that
+// always fail as errors, instead of leaving them to fail at runtime.
+func WithConstantErrors() OptimizerOption {
+	return func(o *Optimizer) {
+		o.constantErrors = true
+	}
+}
+
+func NewOptimizer(opts ...OptimizerOption) *Optimizer {
+	o := &Optimizer{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// optimizerPasses run in order; each sees the tree left by the one before.
+var optimizerPasses = []func(o *Optimizer, n Node) Node{
+	(*Optimizer).removeGroups,
+	(*Optimizer).foldConstants,
+	(*Optimizer).simplifyNegations,
+	(*Optimizer).eliminateDeadBranches,
+}
+
+// Optimize returns an optimized copy of statements. With WithConstantErrors,
+// it also returns every failing constant expression it found, joined.
+func (o *Optimizer) Optimize(statements []Stmt) ([]Stmt, error) {
+	o.errs = nil
+	for _, pass := range optimizerPasses {
+		statements = RewriteProgram(statements, func(n Node) Node { return pass(o, n) })
+	}
+	return statements, errors.Join(o.errs...)
+}
+
+// OptimizeExpr returns an optimized copy of expr.
+func (o *Optimizer) OptimizeExpr(expr Expr) (Expr, error) {
+	o.errs = nil
+	for _, pass := range optimizerPasses {
+		expr = Rewrite(expr, func(n Node) Node { return pass(o, n) }).(Expr)
+	}
+	return expr, errors.Join(o.errs...)
+}
+
+// Optimize optimizes statements with a default Optimizer.
+func Optimize(statements []Stmt) []Stmt {
+	statements, _ = NewOptimizer().Optimize(statements)
+	return statements
+}
+
+// removeGroups drops Group nodes, which only record parentheses the parser
+// has already turned into the shape of the tree.
+func (o *Optimizer) removeGroups(n Node) Node {
+	if g, ok := n.(Group); ok {
+		return WithSpan(g.expr, g)
+	}
+	return n
+}
+
+// foldConstants replaces operations on literals with their result. Because
+// the tree is rewritten bottom-up, whole constant subtrees fold into one
+// literal.
+func (o *Optimizer) foldConstants(n Node) Node {
+	// The operations are the interpreter's own, so folding follows exactly
+	// the same rules as running the program would.
+	var value Value
+	var err error
+	var operator Token
+	switch e := n.(type) {
+	case Binary:
+		if !isLiteral(e.left) || !isLiteral(e.right) {
+			return n
+		}
+		operator = e.operator
+		value, err = binaryOperation(operator, e.left.(Literal).literal, e.right.(Literal).literal)
+	case Unary:
+		if !isLiteral(e.right) {
+			return n
+		}
+		operator = e.operator
+		value, err = unaryOperation(operator, e.right.(Literal).literal)
+	default:
+		return n
+	}
+
+	expr := n.(Expr)
+	if err != nil {
+		if o.constantErrors {
+			o.errs = append(o.errs, &Error{Line: operator.Line, Message: err.Error(), kind: ErrLoxRuntime})
+		}
+		return n
+	}
+	literal := Literal{literal: value, token: literalToken(value, lineOf(expr))}
+	return WithOrigin(literal, expr)
+}
+
+// simplifyNegations cancels !!x and -(-x) where x is already a boolean or a
+// number respectively, and so would come out unchanged. Elsewhere the pair
+// converts or type-checks x, so it has to stay. In an if condition, only
+// truthiness matters, so any !! is dropped.
+func (o *Optimizer) simplifyNegations(n Node) Node {
+	switch n := n.(type) {
+	case Unary:
+		inner, ok := n.right.(Unary)
+		if !ok || inner.operator.TokenType != n.operator.TokenType {
+			return n
+		}
+		if n.operator.TokenType == Bang && isBoolean(inner.right) {
+			return WithSpan(inner.right, n)
+		}
+		if n.operator.TokenType == Minus && isNumeric(inner.right) {
+			return WithSpan(inner.right, n)
+		}
+	case IfStmt:
+		for {
+			outer, ok := n.condition.(Unary)
+			if !ok || outer.operator.TokenType != Bang {
+				break
+			}
+			inner, ok := outer.right.(Unary)
+			if !ok || inner.operator.TokenType != Bang {
+				break
+			}
+			n.condition = WithSpan(inner.right, outer).(Expr)
+		}
+		return n
+	}
+	return n
+}
+
+// eliminateDeadBranches replaces an if statement whose condition is a literal
+// with the branch that will run, or removes it if there is none.
+func (o *Optimizer) eliminateDeadBranches(n Node) Node {
+	s, ok := n.(IfStmt)
+	if !ok {
+		return n
+	}
+	condition, ok := s.condition.(Literal)
+	if !ok {
+		return n
+	}
+	if isTruthy(condition.literal) {
+		return WithSpan(s.thenBranch, s)
+	}
+	if s.elseBranch == nil {
+		return nil
+	}
+	return WithSpan(s.elseBranch, s)
+}
+
+func isLiteral(e Expr) bool {
+	_, ok := e.(Literal)
+	return ok
+}
+
+// isBoolean reports whether e always evaluates to a boolean, if it evaluates
+// without error.
+func isBoolean(e Expr) bool {
+	switch e := e.(type) {
+	case Literal:
+		_, ok := e.literal.(bool)
+		return ok
+	case Unary:
+		return e.operator.TokenType == Bang
+	case Binary:
+		switch e.operator.TokenType {
+		case BangEqual, EqualEqual, Greater, GreaterEqual, Less, LessEqual:
+			return true
+		}
+	}
+	return false
+}
+
+// isNumeric reports whether e always evaluates to a number, if it evaluates
+// without error.
+func isNumeric(e Expr) bool {
+	switch e := e.(type) {
+	case Literal:
+		_, ok := e.literal.(float64)
+		return ok
+	case Unary:
+		return e.operator.TokenType == Minus
+	case Binary:
+		switch e.operator.TokenType {
+		case Minus, Slash, Star:
+			return true
+		}
+	}
+	return false
+}
+
+// literalToken builds the token for a literal the optimizer has computed, as
+// though value had been written on line.
+func literalToken(value Value, line int) Token {
+	switch v := value.(type) {
+	case bool:
+		if v {
+			return NewToken(True, "true", nil, line)
+		}
+		return NewToken(False, "false", nil, line)
+	case float64:
+		return NewToken(Number, Stringify(v), v, line)
+	case string:
+		return NewToken(String, `"`+v+`"`, v, line)
+	}
+	return NewToken(Nil, "nil", nil, line)
+}
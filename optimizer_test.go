@@ -0,0 +1,187 @@
+// ABOUTME: Tests for the AST optimizer's passes and their guarantees
+// ABOUTME: Checks folded trees, preserved runtime errors and constant error reporting
+package lox
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOptimizer(t *testing.T) {
+	tests := []struct {
+		name     string
+		source   string
+		expected []string
+	}{
+		{name: "arithmetic", source: "(1 + 2) * 3;", expected: []string{"(; 9)"}},
+		{name: "nested groups", source: "((x));", expected: []string{"(; x)"}},
+		{name: "string concatenation", source: `"a" + "b" + "c";`, expected: []string{"(; abc)"}},
+		{name: "comparison", source: "1 < 2 == true;", expected: []string{"(; true)"}},
+		{name: "unary", source: "-(2 - 5); !nil;", expected: []string{"(; 3)", "(; true)"}},
+		{name: "partially constant", source: "x + 2 * 3;", expected: []string{"(; (+ x 6))"}},
+		{name: "not left associative", source: "x + 1 + 2;", expected: []string{"(; (+ (+ x 1) 2))"}},
+		{name: "inside other nodes", source: "print [1 + 1, f(2 * 2)];", expected: []string{"(print (list 2 (call f 4)))"}},
+		{name: "failing constant is kept", source: `-"a"; 1 + "b";`, expected: []string{"(; (- a))", "(; (+ 1 b))"}},
+		{name: "double not of boolean", source: "!!(x < y);", expected: []string{"(; (< x y))"}},
+		{name: "double not of other value", source: "!!x;", expected: []string{"(; (! (! x)))"}},
+		{name: "double negation of number", source: "-(-(x * y));", expected: []string{"(; (* x y))"}},
+		{name: "double negation of other value", source: "-(-x);", expected: []string{"(; (- (- x)))"}},
+		{name: "double not in condition", source: "if (!!x) print 1;", expected: []string{"(if x (print 1))"}},
+		{name: "dead then branch", source: "if (false) print 1; print 2;", expected: []string{"(print 2)"}},
+		{name: "dead else branch", source: "if (1 < 2) print 1; else print 2;", expected: []string{"(print 1)"}},
+		{name: "taken else branch", source: "if (nil) print 1; else { print 2; }", expected: []string{"(block (print 2))"}},
+		{name: "dead branch in loop body", source: "for x in xs if (false) print x;", expected: []string{"(for x xs (block))"}},
+		{name: "dead branch in block", source: "{ if (!true) print 1; }", expected: []string{"(block)"}},
+		{name: "dynamic condition", source: "if (x) print 1; else print 2;", expected: []string{"(if x (print 1) (print 2))"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			asrt := assert.New(t)
+			statements, err := NewOptimizer().Optimize(parseProgram(t, tt.source))
+			asrt.NoError(err)
+
+			printed := []string{}
+			for _, stmt := range statements {
+				printed = append(printed, printStmt(stmt))
+			}
+			asrt.Equal(tt.expected, printed)
+		})
+	}
+}
+
+func TestOptimizer_PreservesBehavior(t *testing.T) {
+	tests := []struct {
+		name     string
+		source   string
+		output   string
+		errorMsg string
+	}{
+		{name: "folded output", source: `print (1 + 2) * 3; print "a" + "b"; print 1 / 0;`, output: "9\nab\n+Inf\n"},
+		{name: "unary error keeps its line", source: "print 1;\n-\"a\";", output: "1\n", errorMsg: "[line 2] runtime error: operand to - must be a number"},
+		{name: "binary error keeps its line", source: "var x =\n1 +\n\"a\";", errorMsg: "[line 2] runtime error: operands to + must both be numbers or strings"},
+		{name: "double negation still checks", source: `-(-"a");`, errorMsg: "[line 1] runtime error: operand to - must be a number"},
+		{name: "dead branch error never happens", source: `if (false) -"a"; print "ok";`, output: "ok\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			asrt := assert.New(t)
+			statements, err := NewOptimizer().Optimize(parseProgram(t, tt.source))
+			asrt.NoError(err)
+
+			var out bytes.Buffer
+			_, err = NewInterpreter(WithStdout(&out)).Execute(statements)
+			asrt.Equal(tt.output, out.String())
+			if tt.errorMsg != "" {
+				asrt.EqualError(err, tt.errorMsg)
+				return
+			}
+			asrt.NoError(err)
+		})
+	}
+}
+
+func TestOptimizer_ConstantErrors(t *testing.T) {
+	asrt := assert.New(t)
+	source := "print 1 + 2;\n-\"a\";\nvar x = 1 < \"b\";"
+
+	statements, err := NewOptimizer(WithConstantErrors()).Optimize(parseProgram(t, source))
+	asrt.ErrorIs(err, ErrLoxRuntime)
+	asrt.EqualError(err, "[line 2] runtime error: operand to - must be a number\n[line 3] runtime error: operands to < must both be numbers")
+	asrt.Len(statements, 3)
+}
+
+func TestOptimizer_OptimizeExpr(t *testing.T) {
+	asrt := assert.New(t)
+	expr, err := NewOptimizer().OptimizeExpr(parseSource(t, "\n(2 * 3) + x"))
+	asrt.NoError(err)
+	asrt.Equal("(+ 6 x)", printExpr(expr))
+
+	folded := expr.(Binary).Left().(Literal)
+	asrt.Equal(NewToken(Number, "6", 6.0, 2), folded.Token())
+}
+
+func TestOptimizer_LeavesOriginalUnchanged(t *testing.T) {
+	statements := parseProgram(t, "print (1 + 2);")
+	Optimize(statements)
+	assert.Equal(t, "(print (group (+ 1 2)))", printStmt(statements[0]))
+}
+
+func TestOptimizer_Origins(t *testing.T) {
+	tests := []struct {
+		name     string
+		source   string
+		expected string
+		origin   Origin
+		errorMsg string
+	}{
+		{
+			name:     "removed group",
+			source:   "(\n1 <\n\"a\"\n);",
+			expected: "(; (< 1 a))",
+			origin:   Origin{Pos: Position{Line: 2, Column: 1, Offset: 2}, End: Position{Line: 3, Column: 4, Offset: 9}, Line: 2},
+			errorMsg: "[line 2] runtime error: operands to < must both be numbers",
+		},
+		{
+			name:     "folded constant",
+			source:   "(1 +\n2) *\n3 + x;",
+			expected: "(; (+ 9 x))",
+			origin:   Origin{Pos: Position{Line: 1, Column: 2, Offset: 1}, End: Position{Line: 3, Column: 6, Offset: 15}, Line: 3},
+			errorMsg: "[line 3] runtime error: undefined variable 'x'",
+		},
+		{
+			name:     "cancelled negation",
+			source:   "-\n-\n(x *\n2);",
+			expected: "(; (* x 2))",
+			origin:   Origin{Pos: Position{Line: 1, Column: 1, Offset: 0}, End: Position{Line: 4, Column: 2, Offset: 10}, Line: 3},
+			errorMsg: "[line 3] runtime error: undefined variable 'x'",
+		},
+		{
+			name:     "eliminated branch",
+			source:   "if (true)\n\nprint\n-\"a\";",
+			expected: "(print (- a))",
+			origin:   Origin{Pos: Position{Line: 1, Column: 1, Offset: 0}, End: Position{Line: 4, Column: 5, Offset: 21}, Line: 3},
+			errorMsg: "[line 4] runtime error: operand to - must be a number",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			asrt := assert.New(t)
+			parsed := parseProgram(t, tt.source)
+			statements, err := NewOptimizer().Optimize(parsed)
+			asrt.NoError(err)
+			asrt.Len(statements, 1)
+			asrt.Equal(tt.expected, printStmt(statements[0]))
+
+			var node Node = statements[0]
+			if stmt, ok := node.(ExpressionStmt); ok {
+				node = stmt.Expression()
+			}
+			asrt.Equal(tt.origin, OriginOf(node))
+
+			_, err = NewInterpreter().Execute(statements)
+			asrt.EqualError(err, tt.errorMsg)
+			_, err = NewInterpreter().Execute(parsed)
+			asrt.EqualError(err, tt.errorMsg, "the unoptimized program fails the same way")
+		})
+	}
+}
+
+func TestOptimizer_DebuggerLines(t *testing.T) {
+	asrt := assert.New(t)
+	expr, err := NewOptimizer().OptimizeExpr(parseSource(t, "(1 +\n  2) *\n-3"))
+	asrt.NoError(err)
+
+	var lines []int
+	interp := NewInterpreter()
+	interp.SetHook(hookFunc(func(frames []Frame) {
+		lines = append(lines, frames[len(frames)-1].Line)
+	}))
+	_, err = interp.Interpret(expr)
+	asrt.NoError(err)
+	asrt.Equal([]int{2}, lines, "the folded expression stops where its operator was")
+}
@@ -1,13 +1,19 @@
 package lox
 
 import (
+	"errors"
 	"fmt"
 	"slices"
 )
 
+const maxArguments = 255
+
 type Parser struct {
 	tokens  []Token
 	current int
+
+	// errs are the syntax errors the parser has recovered from so far.
+	errs []error
 }
 
 func NewParser(tokens []Token) *Parser {
@@ -20,8 +26,261 @@ func (p *Parser) Parse() (Expr, error) {
 	return p.expression()
 }
 
+// ParseProgram parses a sequence of declarations and statements. Parsing
+// continues past errors so that every syntax error in the source is reported.
+func (p *Parser) ParseProgram() ([]Stmt, error) {
+	statements := []Stmt{}
+	p.errs = nil
+	for !p.isAtEnd() {
+		start := p.current
+		stmt, err := p.declaration()
+		if err != nil {
+			p.errs = append(p.errs, err)
+			p.synchronize(start)
+			continue
+		}
+		statements = append(statements, stmt)
+	}
+	return statements, errors.Join(p.errs...)
+}
+
+func (p *Parser) declaration() (Stmt, error) {
+	if p.match(Var) {
+		return p.varDeclaration()
+	}
+	return p.statement()
+}
+
+func (p *Parser) varDeclaration() (Stmt, error) {
+	keyword := p.previous()
+	name, err := p.consume(Identifier, "expect variable name")
+	if err != nil {
+		return nil, err
+	}
+
+	var initializer Expr
+	if p.match(Equal) {
+		initializer, err = p.expression()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if _, err := p.consume(Semicolon, "expect ';' after variable declaration"); err != nil {
+		return nil, err
+	}
+	return VarStmt{keyword: keyword, name: name, initializer: initializer}, nil
+}
+
+func (p *Parser) statement() (Stmt, error) {
+	if p.match(Print) {
+		return p.printStatement()
+	}
+	if p.match(For) {
+		return p.forInStatement()
+	}
+	if p.match(If) {
+		return p.ifStatement()
+	}
+	if p.match(Throw) {
+		return p.throwStatement()
+	}
+	if p.match(Try) {
+		return p.tryStatement()
+	}
+	if p.check(LeftBrace) && !p.startsMap() {
+		brace := p.advance()
+		statements, err := p.block()
+		if err != nil {
+			return nil, err
+		}
+		return BlockStmt{brace: brace, statements: statements, closing: p.previous()}, nil
+	}
+	return p.expressionStatement()
+}
+
+func (p *Parser) printStatement() (Stmt, error) {
+	keyword := p.previous()
+	expr, err := p.expression()
+	if err != nil {
+		return nil, err
+	}
+	if _, err := p.consume(Semicolon, "expect ';' after value"); err != nil {
+		return nil, err
+	}
+	return PrintStmt{keyword: keyword, expr: expr}, nil
+}
+
+func (p *Parser) forInStatement() (Stmt, error) {
+	keyword := p.previous()
+	name, err := p.consume(Identifier, "expect loop variable name after 'for'")
+	if err != nil {
+		return nil, err
+	}
+	if _, err := p.consume(In, "expect 'in' after loop variable"); err != nil {
+		return nil, err
+	}
+	iterable, err := p.expression()
+	if err != nil {
+		return nil, err
+	}
+	body, err := p.statement()
+	if err != nil {
+		return nil, err
+	}
+	return ForInStmt{keyword: keyword, name: name, iterable: iterable, body: body}, nil
+}
+
+func (p *Parser) ifStatement() (Stmt, error) {
+	keyword := p.previous()
+	if _, err := p.consume(LeftParen, "expect '(' after 'if'"); err != nil {
+		return nil, err
+	}
+	condition, err := p.expression()
+	if err != nil {
+		return nil, err
+	}
+	if _, err := p.consume(RightParen, "expect ')' after if condition"); err != nil {
+		return nil, err
+	}
+	thenBranch, err := p.statement()
+	if err != nil {
+		return nil, err
+	}
+
+	var elseBranch Stmt
+	if p.match(Else) {
+		if elseBranch, err = p.statement(); err != nil {
+			return nil, err
+		}
+	}
+	return IfStmt{keyword: keyword, condition: condition, thenBranch: thenBranch, elseBranch: elseBranch}, nil
+}
+
+func (p *Parser) throwStatement() (Stmt, error) {
+	keyword := p.previous()
+	value, err := p.expression()
+	if err != nil {
+		return nil, err
+	}
+	if _, err := p.consume(Semicolon, "expect ';' after thrown value"); err != nil {
+		return nil, err
+	}
+	return ThrowStmt{keyword: keyword, value: value}, nil
+}
+
+func (p *Parser) tryStatement() (Stmt, error) {
+	keyword := p.previous()
+	if _, err := p.consume(LeftBrace, "expect '{' after 'try'"); err != nil {
+		return nil, err
+	}
+	body, err := p.block()
+	if err != nil {
+		return nil, err
+	}
+	stmt := TryStmt{keyword: keyword, body: body}
+
+	if p.match(Catch) {
+		if _, err := p.consume(LeftParen, "expect '(' after 'catch'"); err != nil {
+			return nil, err
+		}
+		if stmt.catchName, err = p.consume(Identifier, "expect exception variable name"); err != nil {
+			return nil, err
+		}
+		if _, err := p.consume(RightParen, "expect ')' after exception variable"); err != nil {
+			return nil, err
+		}
+		if _, err := p.consume(LeftBrace, "expect '{' before catch body"); err != nil {
+			return nil, err
+		}
+		if stmt.catchBody, err = p.block(); err != nil {
+			return nil, err
+		}
+	}
+
+	if p.match(Finally) {
+		if _, err := p.consume(LeftBrace, "expect '{' after 'finally'"); err != nil {
+			return nil, err
+		}
+		if stmt.finallyBody, err = p.block(); err != nil {
+			return nil, err
+		}
+	}
+
+	if stmt.catchBody == nil && stmt.finallyBody == nil {
+		return nil, p.reportError("expect 'catch' or 'finally' after try block")
+	}
+	stmt.closing = p.previous()
+	return stmt, nil
+}
+
+// block parses statements up to and including the closing brace, which is
+// left in p.previous().
+// block parses the statements up to the closing brace. It recovers from
+// errors in them the way ParseProgram does, so that an error in a block
+// doesn't stop the statement the block belongs to from being parsed.
+func (p *Parser) block() ([]Stmt, error) {
+	statements := []Stmt{}
+	for !p.check(RightBrace) && !p.isAtEnd() {
+		start := p.current
+		stmt, err := p.declaration()
+		if err != nil {
+			p.errs = append(p.errs, err)
+			p.synchronize(start)
+			continue
+		}
+		statements = append(statements, stmt)
+	}
+
+	if _, err := p.consume(RightBrace, "expect '}' after block"); err != nil {
+		return nil, err
+	}
+	return statements, nil
+}
+
+// expressionStatement parses an expression followed by a semicolon. The
+// semicolon may be left off the last statement in the source, so a bare
+// expression is still a valid program.
+func (p *Parser) expressionStatement() (Stmt, error) {
+	expr, err := p.expression()
+	if err != nil {
+		return nil, err
+	}
+	if !p.isAtEnd() {
+		if _, err := p.consume(Semicolon, "expect ';' after expression"); err != nil {
+			return nil, err
+		}
+	}
+	return ExpressionStmt{expr: expr}, nil
+}
+
 func (p *Parser) expression() (Expr, error) {
-	return p.equality()
+	return p.assignment()
+}
+
+func (p *Parser) assignment() (Expr, error) {
+	expr, err := p.equality()
+	if err != nil {
+		return nil, err
+	}
+
+	if p.match(Equal) {
+		equals := p.previous()
+		value, err := p.assignment()
+		if err != nil {
+			return nil, err
+		}
+
+		switch target := expr.(type) {
+		case Variable:
+			return Assign{name: target.name, value: value}, nil
+		case Index:
+			return SetIndex{object: target.object, bracket: target.bracket, index: target.index, value: value}, nil
+		}
+		return nil, &Error{Line: equals.Line, Where: "at '='", Message: "invalid assignment target", kind: ErrLoxSyntax}
+	}
+
+	return expr, nil
 }
 
 func (p *Parser) equality() (Expr, error) {
@@ -110,22 +369,98 @@ func (p *Parser) unary() (Expr, error) {
 		return Unary{operator: op, right: right}, nil
 	}
 
-	return p.primary()
+	return p.call()
+}
+
+func (p *Parser) call() (Expr, error) {
+	expr, err := p.primary()
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		switch {
+		case p.match(LeftParen):
+			expr, err = p.finishCall(expr)
+		case p.match(LeftBracket):
+			expr, err = p.finishIndex(expr)
+		case p.match(Dot):
+			var name Token
+			name, err = p.consume(Identifier, "expect property name after '.'")
+			expr = Get{object: expr, name: name}
+		default:
+			return expr, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (p *Parser) finishIndex(object Expr) (Expr, error) {
+	bracket := p.previous()
+	index, err := p.expression()
+	if err != nil {
+		return nil, err
+	}
+	if _, err := p.consume(RightBracket, "expect ']' after index"); err != nil {
+		return nil, err
+	}
+	return Index{object: object, bracket: bracket, index: index}, nil
+}
+
+func (p *Parser) finishCall(callee Expr) (Expr, error) {
+	arguments := []Expr{}
+	if !p.check(RightParen) {
+		for {
+			if len(arguments) >= maxArguments {
+				return nil, p.reportError(fmt.Sprintf("can't have more than %d arguments", maxArguments))
+			}
+			arg, err := p.expression()
+			if err != nil {
+				return nil, err
+			}
+			arguments = append(arguments, arg)
+
+			if !p.match(Comma) {
+				break
+			}
+		}
+	}
+
+	paren, err := p.consume(RightParen, "expect ')' after arguments")
+	if err != nil {
+		return nil, err
+	}
+
+	return Call{callee: callee, paren: paren, arguments: arguments}, nil
 }
 
 func (p *Parser) primary() (Expr, error) {
 	if p.match(False) {
-		return Literal{literal: false}, nil
+		return Literal{literal: false, token: p.previous()}, nil
 	}
 	if p.match(True) {
-		return Literal{literal: true}, nil
+		return Literal{literal: true, token: p.previous()}, nil
 	}
 	if p.match(Nil) {
-		return Literal{literal: nil}, nil
+		return Literal{literal: nil, token: p.previous()}, nil
 	}
 
 	if p.match(Number, String) {
-		return Literal{literal: p.previous().Object}, nil
+		return Literal{literal: p.previous().Object, token: p.previous()}, nil
+	}
+
+	if p.match(Identifier) {
+		return Variable{name: p.previous()}, nil
+	}
+
+	if p.match(LeftBracket) {
+		return p.list()
+	}
+
+	if p.match(LeftBrace) {
+		return p.mapLiteral()
 	}
 
 	if p.match(LeftParen) {
@@ -145,6 +480,77 @@ func (p *Parser) primary() (Expr, error) {
 	return nil, err
 }
 
+func (p *Parser) list() (Expr, error) {
+	bracket := p.previous()
+	elements := []Expr{}
+	if !p.check(RightBracket) {
+		for {
+			element, err := p.expression()
+			if err != nil {
+				return nil, err
+			}
+			elements = append(elements, element)
+
+			if !p.match(Comma) {
+				break
+			}
+		}
+	}
+
+	closing, err := p.consume(RightBracket, "expect ']' after list elements")
+	if err != nil {
+		return nil, err
+	}
+	return ListLiteral{bracket: bracket, elements: elements, closing: closing}, nil
+}
+
+func (p *Parser) mapLiteral() (Expr, error) {
+	brace := p.previous()
+	keys, values := []Expr{}, []Expr{}
+	if !p.check(RightBrace) {
+		for {
+			key, err := p.expression()
+			if err != nil {
+				return nil, err
+			}
+			if _, err := p.consume(Colon, "expect ':' after map key"); err != nil {
+				return nil, err
+			}
+			value, err := p.expression()
+			if err != nil {
+				return nil, err
+			}
+			keys = append(keys, key)
+			values = append(values, value)
+
+			if !p.match(Comma) {
+				break
+			}
+		}
+	}
+
+	closing, err := p.consume(RightBrace, "expect '}' after map entries")
+	if err != nil {
+		return nil, err
+	}
+	return MapLiteral{brace: brace, keys: keys, values: values, closing: closing}, nil
+}
+
+// startsMap reports whether the '{' at the start of a statement opens a map
+// literal rather than a block. It looks for a simple key followed by ':', so
+// a map whose first key is a more complex expression must be wrapped in
+// parentheses to be used as a statement. An empty '{}' is always a block.
+func (p *Parser) startsMap() bool {
+	if p.current+2 >= len(p.tokens) {
+		return false
+	}
+	switch p.tokens[p.current+1].TokenType {
+	case String, Number, Identifier, True, False, Nil:
+		return p.tokens[p.current+2].TokenType == Colon
+	}
+	return false
+}
+
 func (p *Parser) consume(tokenType TokenType, msg string) (Token, error) {
 	if p.check(tokenType) {
 		return p.advance(), nil
@@ -198,11 +604,17 @@ func (p *Parser) reportError(msg string) error {
 		location = "at end"
 	}
 
-	return fmt.Errorf("[line %d] %w %s: %s", token.Line, ErrLoxSyntax, location, msg)
+	return &Error{Line: token.Line, Where: location, Message: msg, kind: ErrLoxSyntax}
 }
 
-func (p *Parser) synchronize() {
-	p.advance()
+// synchronize skips to the start of the next statement after a syntax error
+// in the declaration that began at start. The token the error was reported
+// at may itself start the next statement, as a try or throw after a missing
+// semicolon does, so it is only skipped if the declaration began there too.
+func (p *Parser) synchronize(start int) {
+	if p.current == start {
+		p.advance()
+	}
 
 	for !p.isAtEnd() {
 		if p.previous().TokenType == Semicolon {
@@ -210,7 +622,7 @@ func (p *Parser) synchronize() {
 		}
 
 		switch p.peek().TokenType {
-		case Class, Fun, Var, For, If, While, Print, Return:
+		case Class, Fun, Var, For, If, While, Print, Return, Throw, Try:
 			return
 		}
 
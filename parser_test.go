@@ -29,6 +29,38 @@ func (tv *testVisitor) VisitLiteral(l Literal) {
 	tv.result = l.literal
 }
 
+func (tv *testVisitor) VisitVariable(v Variable) {
+	tv.result = "variable expression"
+}
+
+func (tv *testVisitor) VisitCall(c Call) {
+	tv.result = "call expression"
+}
+
+func (tv *testVisitor) VisitAssign(a Assign) {
+	tv.result = "assign expression"
+}
+
+func (tv *testVisitor) VisitList(l ListLiteral) {
+	tv.result = "list expression"
+}
+
+func (tv *testVisitor) VisitMap(m MapLiteral) {
+	tv.result = "map expression"
+}
+
+func (tv *testVisitor) VisitIndex(i Index) {
+	tv.result = "index expression"
+}
+
+func (tv *testVisitor) VisitSetIndex(s SetIndex) {
+	tv.result = "set index expression"
+}
+
+func (tv *testVisitor) VisitGet(g Get) {
+	tv.result = "get expression"
+}
+
 func TestParser_Expressions(t *testing.T) {
 	tests := []struct {
 		name         string
@@ -406,6 +438,116 @@ func TestParser_Expressions(t *testing.T) {
 			expectedAST: "(== hello world)",
 		},
 
+		// Variables and calls - testing call() and primary()
+		{
+			name: "variable",
+			tokens: []Token{
+				NewToken(Identifier, "answer", nil, 1),
+				NewToken(EOF, "", nil, 1),
+			},
+			expectedAST: "answer",
+		},
+		{
+			name: "call: no arguments",
+			tokens: []Token{
+				NewToken(Identifier, "clock", nil, 1),
+				NewToken(LeftParen, "(", nil, 1),
+				NewToken(RightParen, ")", nil, 1),
+				NewToken(EOF, "", nil, 1),
+			},
+			expectedAST: "(call clock)",
+		},
+		{
+			name: "call: multiple arguments",
+			tokens: []Token{
+				NewToken(Identifier, "max", nil, 1),
+				NewToken(LeftParen, "(", nil, 1),
+				NewToken(Number, "1", 1.0, 1),
+				NewToken(Comma, ",", nil, 1),
+				NewToken(Minus, "-", nil, 1),
+				NewToken(Identifier, "x", nil, 1),
+				NewToken(RightParen, ")", nil, 1),
+				NewToken(EOF, "", nil, 1),
+			},
+			expectedAST: "(call max 1 (- x))",
+		},
+		{
+			name: "call: chained calls bind tighter than unary",
+			tokens: []Token{
+				NewToken(Bang, "!", nil, 1),
+				NewToken(Identifier, "f", nil, 1),
+				NewToken(LeftParen, "(", nil, 1),
+				NewToken(RightParen, ")", nil, 1),
+				NewToken(LeftParen, "(", nil, 1),
+				NewToken(RightParen, ")", nil, 1),
+				NewToken(EOF, "", nil, 1),
+			},
+			expectedAST: "(! (call (call f)))",
+		},
+
+		// Lists, indexing and properties
+		{
+			name: "list: literal",
+			tokens: []Token{
+				NewToken(LeftBracket, "[", nil, 1),
+				NewToken(Number, "1", 1.0, 1),
+				NewToken(Comma, ",", nil, 1),
+				NewToken(Identifier, "x", nil, 1),
+				NewToken(RightBracket, "]", nil, 1),
+				NewToken(EOF, "", nil, 1),
+			},
+			expectedAST: "(list 1 x)",
+		},
+		{
+			name: "list: empty",
+			tokens: []Token{
+				NewToken(LeftBracket, "[", nil, 1),
+				NewToken(RightBracket, "]", nil, 1),
+				NewToken(EOF, "", nil, 1),
+			},
+			expectedAST: "(list)",
+		},
+		{
+			name: "index: chained with call and property",
+			tokens: []Token{
+				NewToken(Identifier, "xs", nil, 1),
+				NewToken(LeftBracket, "[", nil, 1),
+				NewToken(Number, "0", 0.0, 1),
+				NewToken(RightBracket, "]", nil, 1),
+				NewToken(Dot, ".", nil, 1),
+				NewToken(Identifier, "pop", nil, 1),
+				NewToken(LeftParen, "(", nil, 1),
+				NewToken(RightParen, ")", nil, 1),
+				NewToken(EOF, "", nil, 1),
+			},
+			expectedAST: "(call (. (index xs 0) pop))",
+		},
+		{
+			name: "assignment: variable is right associative",
+			tokens: []Token{
+				NewToken(Identifier, "a", nil, 1),
+				NewToken(Equal, "=", nil, 1),
+				NewToken(Identifier, "b", nil, 1),
+				NewToken(Equal, "=", nil, 1),
+				NewToken(Number, "1", 1.0, 1),
+				NewToken(EOF, "", nil, 1),
+			},
+			expectedAST: "(= a (= b 1))",
+		},
+		{
+			name: "assignment: index",
+			tokens: []Token{
+				NewToken(Identifier, "xs", nil, 1),
+				NewToken(LeftBracket, "[", nil, 1),
+				NewToken(Number, "0", 0.0, 1),
+				NewToken(RightBracket, "]", nil, 1),
+				NewToken(Equal, "=", nil, 1),
+				NewToken(Number, "1", 1.0, 1),
+				NewToken(EOF, "", nil, 1),
+			},
+			expectedAST: "(= (index xs 0) 1)",
+		},
+
 		// Error cases
 		{
 			name: "error: missing closing paren",
@@ -425,6 +567,38 @@ func TestParser_Expressions(t *testing.T) {
 			wantErr:      true,
 			errorMessage: "expect expression",
 		},
+		{
+			name: "error: unterminated call",
+			tokens: []Token{
+				NewToken(Identifier, "f", nil, 1),
+				NewToken(LeftParen, "(", nil, 1),
+				NewToken(Number, "1", 1.0, 1),
+				NewToken(EOF, "", nil, 1),
+			},
+			wantErr:      true,
+			errorMessage: "expect ')' after arguments",
+		},
+		{
+			name: "error: unterminated list",
+			tokens: []Token{
+				NewToken(LeftBracket, "[", nil, 1),
+				NewToken(Number, "1", 1.0, 1),
+				NewToken(EOF, "", nil, 1),
+			},
+			wantErr:      true,
+			errorMessage: "expect ']' after list elements",
+		},
+		{
+			name: "error: invalid assignment target",
+			tokens: []Token{
+				NewToken(Number, "1", 1.0, 1),
+				NewToken(Equal, "=", nil, 1),
+				NewToken(Number, "2", 2.0, 1),
+				NewToken(EOF, "", nil, 1),
+			},
+			wantErr:      true,
+			errorMessage: "invalid assignment target",
+		},
 		{
 			name: "error: unexpected token",
 			tokens: []Token{
@@ -459,3 +633,87 @@ func TestParser_Expressions(t *testing.T) {
 		})
 	}
 }
+
+func TestParser_Program(t *testing.T) {
+	tests := []struct {
+		name     string
+		source   string
+		expected []string
+		errorMsg string
+	}{
+		{name: "bare expression", source: "1 + 2", expected: []string{"(; (+ 1 2))"}},
+		{name: "expression statements", source: "1; 2;", expected: []string{"(; 1)", "(; 2)"}},
+		{name: "var", source: "var x; var y = [1];", expected: []string{"(var x)", "(var y (list 1))"}},
+		{name: "print", source: "print x;", expected: []string{"(print x)"}},
+		{name: "block", source: "{ var x = 1; print x; }", expected: []string{"(block (var x 1) (print x))"}},
+		{name: "for in", source: "for x in xs { print x; }", expected: []string{"(for x xs (block (print x)))"}},
+		{name: "map literal", source: `var m = {"a": 1, 2: [3]};`, expected: []string{`(var m (map a 1 2 (list 3)))`}},
+		{name: "empty braces are a block", source: "{}", expected: []string{"(block)"}},
+		{name: "map statement", source: `{"a": 1}`, expected: []string{"(; (map a 1))"}},
+		{name: "block starting with a variable", source: "{ x; }", expected: []string{"(block (; x))"}},
+		{name: "empty map expression", source: "var m = {};", expected: []string{"(var m (map))"}},
+		{name: "map missing colon", source: `var m = {"a" 1};`, errorMsg: "[line 1] syntax error at '1': expect ':' after map key"},
+		{name: "if", source: "if (x) print 1;", expected: []string{"(if x (print 1))"}},
+		{name: "if else", source: "if (x) { 1; } else print 2;", expected: []string{"(if x (block (; 1)) (print 2))"}},
+		{name: "else binds to nearest if", source: "if (a) if (b) 1; else 2;", expected: []string{"(if a (if b (; 1) (; 2)))"}},
+		{name: "if without parens", source: "if x print 1;", errorMsg: "[line 1] syntax error at 'x': expect '(' after 'if'"},
+		{name: "throw", source: `throw "x";`, expected: []string{"(throw x)"}},
+		{
+			name:     "try catch finally",
+			source:   "try { f(); } catch (e) { print e; } finally { g(); }",
+			expected: []string{"(try (block (; (call f))) (catch e (block (print e))) (finally (block (; (call g)))))"},
+		},
+		{name: "try finally", source: "try {} finally {}", expected: []string{"(try (block) (finally (block)))"}},
+		{name: "try without handlers", source: "try {}", errorMsg: "[line 1] syntax error at end: expect 'catch' or 'finally' after try block"},
+		{name: "catch without parens", source: "try {} catch e {}", errorMsg: "[line 1] syntax error at 'e': expect '(' after 'catch'"},
+		{name: "missing semicolon", source: "1 2", errorMsg: "[line 1] syntax error at '2': expect ';' after expression"},
+		{name: "missing in", source: "for x xs print x;", errorMsg: "[line 1] syntax error at 'xs': expect 'in' after loop variable"},
+		{
+			name:     "reports every error",
+			source:   "var = 1;\nprint ;\nvar ok = 1;",
+			errorMsg: "[line 1] syntax error at '=': expect variable name\n[line 2] syntax error at ';': expect expression",
+		},
+		{
+			name:     "try after an error at it",
+			source:   "print 1\ntry { print ; } finally {}\nvar ok = 1;",
+			errorMsg: "[line 2] syntax error at 'try': expect ';' after value\n[line 2] syntax error at ';': expect expression",
+		},
+		{
+			name:     "throw after an error at it",
+			source:   "var a = (1\nthrow ;\nprint a;",
+			errorMsg: "[line 2] syntax error at 'throw': expect ')' after expression\n[line 2] syntax error at ';': expect expression",
+		},
+		{
+			name:     "errors in blocks",
+			source:   "if (x) { print ; } else { var = 1; }\ntry { print ; } finally { print ; }\nprint ;",
+			errorMsg: "[line 1] syntax error at ';': expect expression\n[line 1] syntax error at '=': expect variable name\n[line 2] syntax error at ';': expect expression\n[line 2] syntax error at ';': expect expression\n[line 3] syntax error at ';': expect expression",
+		},
+		{
+			name:     "try after an error",
+			source:   "print ;\ntry {} catch e {}",
+			errorMsg: "[line 1] syntax error at ';': expect expression\n[line 2] syntax error at 'e': expect '(' after 'catch'",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			asrt := assert.New(t)
+			tokens, err := NewScanner(tt.source).ScanTokens()
+			asrt.NoError(err)
+			statements, err := NewParser(tokens).ParseProgram()
+
+			if tt.errorMsg != "" {
+				asrt.ErrorIs(err, ErrLoxSyntax)
+				asrt.EqualError(err, tt.errorMsg)
+				return
+			}
+
+			asrt.NoError(err)
+			actual := []string{}
+			for _, stmt := range statements {
+				actual = append(actual, printStmt(stmt))
+			}
+			asrt.Equal(tt.expected, actual)
+		})
+	}
+}
@@ -0,0 +1,197 @@
+package lox
+
+import "strings"
+
+// Position is a location in Lox source: a 1-based line and column, with
+// columns counted in bytes, and the 0-based byte offset from the start of
+// the source. Nodes built from tokens that were not scanned, such as with
+// NewToken, have positions with only a line, and a Column of 0.
+type Position struct {
+	Line   int `json:"line"`
+	Column int `json:"column"`
+	Offset int `json:"offset"`
+}
+
+// before reports whether p comes earlier in the source than q.
+func (p Position) before(q Position) bool {
+	if p.Line != q.Line {
+		return p.Line < q.Line
+	}
+	return p.Offset < q.Offset
+}
+
+// Node is implemented by every expression and statement. Pos is where the
+// node's source starts and End is just past where it finishes, so the node's
+// source is the bytes from Pos.Offset up to End.Offset.
+type Node interface {
+	Pos() Position
+	End() Position
+}
+
+// tokenPos is the position of the first byte of t.
+func tokenPos(t Token) Position {
+	line := t.Line - strings.Count(t.Lexeme, "\n")
+	if t.Column == 0 {
+		return Position{Line: line}
+	}
+	return Position{Line: line, Column: t.Column, Offset: t.Offset}
+}
+
+// tokenEnd is the position just past the last byte of t.
+func tokenEnd(t Token) Position {
+	if t.Column == 0 {
+		return Position{Line: t.Line}
+	}
+	column := t.Column + len(t.Lexeme)
+	if nl := strings.LastIndexByte(t.Lexeme, '\n'); nl >= 0 {
+		column = len(t.Lexeme) - nl
+	}
+	return Position{Line: t.Line, Column: column, Offset: t.Offset + len(t.Lexeme)}
+}
+
+// Origin is the source a node stands for. Line is where errors and the
+// debugger place the node itself, such as the operator of a binary
+// expression.
+type Origin struct {
+	Pos  Position `json:"pos"`
+	End  Position `json:"end"`
+	Line int      `json:"line"`
+}
+
+// OriginOf returns the source n stands for. For nodes straight from the
+// parser, that's their own span; nodes put in place by a transformation
+// report the span of the source they replaced.
+func OriginOf(n Node) Origin {
+	line := n.Pos().Line
+	if e, ok := n.(Expr); ok {
+		line = lineOf(e)
+	} else if o, ok := n.(interface{ source() *Origin }); ok && o.source() != nil {
+		line = o.source().Line
+	}
+	return Origin{Pos: n.Pos(), End: n.End(), Line: line}
+}
+
+// WithOrigin returns a copy of n that stands for the source of from. Code that
+// rewrites trees should wrap each replacement node with it, so that errors and
+// debugger locations keep pointing at the source the user wrote. Origins
+// carry through repeated rewrites, always leading back to the parsed source.
+func WithOrigin(n, from Node) Node {
+	return setOrigin(n, OriginOf(from))
+}
+
+// WithSpan returns a copy of n whose span also covers the source of from,
+// keeping n's own line. Code that rewrites trees should wrap a node with it
+// when the node survives in place of an enclosing one, such as the branch
+// that replaces an if statement, so that errors inside it are still
+// reported where they are while the node stands for all the source it
+// replaced.
+func WithSpan(n, from Node) Node {
+	o, span := OriginOf(n), OriginOf(from)
+	if span.Pos.before(o.Pos) {
+		o.Pos = span.Pos
+	}
+	if o.End.before(span.End) {
+		o.End = span.End
+	}
+	return setOrigin(n, o)
+}
+
+func setOrigin(n Node, o Origin) Node {
+	at := origin{from: &o}
+
+	switch n := n.(type) {
+	case Binary:
+		n.origin = at
+		return n
+	case Unary:
+		n.origin = at
+		return n
+	case Group:
+		n.origin = at
+		return n
+	case Literal:
+		n.origin = at
+		return n
+	case Variable:
+		n.origin = at
+		return n
+	case Call:
+		n.origin = at
+		return n
+	case Assign:
+		n.origin = at
+		return n
+	case ListLiteral:
+		n.origin = at
+		return n
+	case MapLiteral:
+		n.origin = at
+		return n
+	case Index:
+		n.origin = at
+		return n
+	case SetIndex:
+		n.origin = at
+		return n
+	case Get:
+		n.origin = at
+		return n
+	case ExpressionStmt:
+		n.origin = at
+		return n
+	case PrintStmt:
+		n.origin = at
+		return n
+	case VarStmt:
+		n.origin = at
+		return n
+	case BlockStmt:
+		n.origin = at
+		return n
+	case ForInStmt:
+		n.origin = at
+		return n
+	case IfStmt:
+		n.origin = at
+		return n
+	case ThrowStmt:
+		n.origin = at
+		return n
+	case TryStmt:
+		n.origin = at
+		return n
+	}
+	return n
+}
+
+// origin is embedded in every node. from is nil for nodes straight from the
+// parser, which stand for their own source.
+type origin struct {
+	from *Origin
+}
+
+// source is the source a transformed node stands for, or nil.
+func (o origin) source() *Origin {
+	return o.from
+}
+
+func (o origin) startOr(pos Position) Position {
+	if o.from != nil {
+		return o.from.Pos
+	}
+	return pos
+}
+
+func (o origin) endOr(pos Position) Position {
+	if o.from != nil {
+		return o.from.End
+	}
+	return pos
+}
+
+func (o origin) lineOr(line int) int {
+	if o.from != nil {
+		return o.from.Line
+	}
+	return line
+}
@@ -0,0 +1,166 @@
+// ABOUTME: Tests for source spans of parsed nodes and for building trees
+// ABOUTME: with the exported constructors and accessors
+package lox
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func parseProgram(t *testing.T, source string) []Stmt {
+	t.Helper()
+	tokens, err := NewScanner(source).ScanTokens()
+	assert.NoError(t, err)
+	statements, err := NewParser(tokens).ParseProgram()
+	assert.NoError(t, err)
+	return statements
+}
+
+func TestNode_Span(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		pos    Position
+		end    Position
+	}{
+		{name: "literal", source: "\n\n42", pos: Position{Line: 3, Column: 1, Offset: 2}, end: Position{Line: 3, Column: 3, Offset: 4}},
+		{name: "binary", source: "1 +\n2 *\n3", pos: Position{Line: 1, Column: 1, Offset: 0}, end: Position{Line: 3, Column: 2, Offset: 9}},
+		{name: "unary", source: "-\n\nx", pos: Position{Line: 1, Column: 1, Offset: 0}, end: Position{Line: 3, Column: 2, Offset: 4}},
+		{name: "group", source: "(\n1\n)", pos: Position{Line: 2, Column: 1, Offset: 2}, end: Position{Line: 2, Column: 2, Offset: 3}},
+		{name: "call", source: "f(\n1,\n2\n)", pos: Position{Line: 1, Column: 1, Offset: 0}, end: Position{Line: 4, Column: 2, Offset: 9}},
+		{name: "list", source: "[\n1\n]", pos: Position{Line: 1, Column: 1, Offset: 0}, end: Position{Line: 3, Column: 2, Offset: 5}},
+		{name: "map", source: "var m = {\n\"a\": 1\n};", pos: Position{Line: 1, Column: 9, Offset: 8}, end: Position{Line: 3, Column: 2, Offset: 18}},
+		{name: "multi-line string", source: "\"a\nb\"", pos: Position{Line: 1, Column: 1, Offset: 0}, end: Position{Line: 2, Column: 3, Offset: 5}},
+		{name: "index", source: "xs\n[0]", pos: Position{Line: 1, Column: 1, Offset: 0}, end: Position{Line: 2, Column: 3, Offset: 5}},
+		{name: "get", source: "xs\n.len", pos: Position{Line: 1, Column: 1, Offset: 0}, end: Position{Line: 2, Column: 5, Offset: 7}},
+		{name: "assign", source: "x =\n1", pos: Position{Line: 1, Column: 1, Offset: 0}, end: Position{Line: 2, Column: 2, Offset: 5}},
+		{name: "print", source: "print\n1;", pos: Position{Line: 1, Column: 1, Offset: 0}, end: Position{Line: 2, Column: 2, Offset: 7}},
+		{name: "var without initializer", source: "var\nx;", pos: Position{Line: 1, Column: 1, Offset: 0}, end: Position{Line: 2, Column: 2, Offset: 5}},
+		{name: "block", source: "{\nx;\n}", pos: Position{Line: 1, Column: 1, Offset: 0}, end: Position{Line: 3, Column: 2, Offset: 6}},
+		{name: "for in", source: "for x in xs {\n}", pos: Position{Line: 1, Column: 1, Offset: 0}, end: Position{Line: 2, Column: 2, Offset: 15}},
+		{name: "if", source: "if (x)\n1;", pos: Position{Line: 1, Column: 1, Offset: 0}, end: Position{Line: 2, Column: 2, Offset: 8}},
+		{name: "if else", source: "if (x)\n1;\nelse\n2;", pos: Position{Line: 1, Column: 1, Offset: 0}, end: Position{Line: 4, Column: 2, Offset: 16}},
+		{name: "throw", source: "throw\n1;", pos: Position{Line: 1, Column: 1, Offset: 0}, end: Position{Line: 2, Column: 2, Offset: 7}},
+		{name: "try", source: "try {\n} catch (e) {\n} finally {\n}", pos: Position{Line: 1, Column: 1, Offset: 0}, end: Position{Line: 4, Column: 2, Offset: 33}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			asrt := assert.New(t)
+			statements := parseProgram(t, tt.source)
+			asrt.Len(statements, 1)
+
+			var node Node = statements[0]
+			if stmt, ok := statements[0].(ExpressionStmt); ok {
+				node = stmt.Expression()
+			}
+			if stmt, ok := statements[0].(VarStmt); ok && stmt.Initializer() != nil {
+				node = stmt.Initializer()
+			}
+			asrt.Equal(tt.pos, node.Pos())
+			asrt.Equal(tt.end, node.End())
+		})
+	}
+}
+
+func TestConstructors(t *testing.T) {
+	asrt := assert.New(t)
+	one := NewLiteral(1.0, NewToken(Number, "1", 1.0, 1))
+	two := NewLiteral(2.0, NewToken(Number, "2", 2.0, 2))
+	sum := NewBinary(one, NewToken(Plus, "+", nil, 1), two)
+	neg := NewUnary(NewToken(Minus, "-", nil, 1), NewGroup(sum))
+	call := NewCall(NewVariable(NewToken(Identifier, "max", nil, 3)), NewToken(RightParen, ")", nil, 3), neg, one)
+	list := NewListLiteral(NewToken(LeftBracket, "[", nil, 3), []Expr{call}, NewToken(RightBracket, "]", nil, 3))
+	index := NewIndex(list, NewToken(LeftBracket, "[", nil, 3), NewLiteral(0.0, NewToken(Number, "0", 0.0, 3)))
+
+	asrt.Equal("(index (list (call max (- (group (+ 1 2))) 1)) 0)", (&AstPrinter{}).Print(index))
+	asrt.Equal(Position{Line: 3}, index.Pos())
+
+	value, err := NewInterpreter().Interpret(index)
+	asrt.NoError(err)
+	asrt.Equal(1.0, value)
+
+	asrt.Equal(one, sum.Left())
+	asrt.Equal(two, sum.Right())
+	asrt.Equal(Plus, sum.Operator().TokenType)
+	asrt.Equal(sum, neg.Right().(Group).Expression())
+	asrt.Equal([]Expr{neg, one}, call.Arguments())
+	asrt.Equal(1.0, one.Value())
+	asrt.Panics(func() { NewMapLiteral(Token{}, []Expr{one}, nil, Token{}) })
+}
+
+func TestStmtConstructors(t *testing.T) {
+	asrt := assert.New(t)
+	tok := func(tokenType TokenType, lexeme string, line int) Token {
+		return NewToken(tokenType, lexeme, nil, line)
+	}
+	number := func(n float64, line int) Literal {
+		return NewLiteral(n, NewToken(Number, Stringify(n), n, line))
+	}
+	total := tok(Identifier, "total", 1)
+
+	statements := []Stmt{
+		NewVarStmt(tok(Var, "var", 1), total, number(0, 1)),
+		NewForInStmt(tok(For, "for", 2), tok(Identifier, "x", 2),
+			NewListLiteral(tok(LeftBracket, "[", 2), []Expr{number(1, 2), number(2, 2)}, tok(RightBracket, "]", 2)),
+			NewBlockStmt(tok(LeftBrace, "{", 2), []Stmt{
+				NewExpressionStmt(NewAssign(total, NewBinary(NewVariable(total), tok(Plus, "+", 3), NewVariable(tok(Identifier, "x", 3))))),
+			}, tok(RightBrace, "}", 4))),
+		NewIfStmt(tok(If, "if", 5), NewVariable(total), NewPrintStmt(tok(Print, "print", 5), NewVariable(total)), nil),
+		NewTryStmt(tok(Try, "try", 6), []Stmt{NewThrowStmt(tok(Throw, "throw", 6), number(4, 6))},
+			tok(Identifier, "e", 7), []Stmt{NewPrintStmt(tok(Print, "print", 7), NewVariable(tok(Identifier, "e", 7)))},
+			nil, tok(RightBrace, "}", 8)),
+	}
+
+	var out bytes.Buffer
+	_, err := NewInterpreter(WithStdout(&out)).Execute(statements)
+	asrt.NoError(err)
+	asrt.Equal("3\n4\n", out.String())
+
+	tryStmt := statements[3].(TryStmt)
+	asrt.Equal(Position{Line: 6}, tryStmt.Pos())
+	asrt.Equal(Position{Line: 8}, tryStmt.End())
+	asrt.Equal("e", tryStmt.CatchName().Lexeme)
+	asrt.Nil(tryStmt.FinallyBody())
+	asrt.Nil(statements[2].(IfStmt).Else())
+	asrt.Equal(Position{Line: 4}, statements[1].End())
+
+	finallyOnly := NewTryStmt(tok(Try, "try", 1), nil, tok(Identifier, "e", 1), nil, []Stmt{}, tok(RightBrace, "}", 1))
+	asrt.Equal(Token{}, finallyOnly.CatchName(), "the catch name is dropped without a catch clause")
+	asrt.Panics(func() { NewTryStmt(tok(Try, "try", 1), nil, Token{}, nil, nil, tok(RightBrace, "}", 1)) })
+}
+
+func TestWithOrigin(t *testing.T) {
+	asrt := assert.New(t)
+	sum := parseSource(t, "1 +\n2 +\n\n3")
+	replacement := NewLiteral(6.0, NewToken(Number, "6", 6.0, 9))
+
+	moved := WithOrigin(replacement, sum).(Literal)
+	asrt.Equal(Origin{Pos: Position{Line: 1, Column: 1, Offset: 0}, End: Position{Line: 4, Column: 2, Offset: 10}, Line: 2}, OriginOf(moved))
+	asrt.Equal(Position{Line: 1, Column: 1, Offset: 0}, moved.Pos())
+	asrt.Equal(Position{Line: 4, Column: 2, Offset: 10}, moved.End())
+	asrt.Equal(6.0, moved.Value())
+	asrt.Equal(Origin{Pos: Position{Line: 9}, End: Position{Line: 9}, Line: 9}, OriginOf(replacement), "the original is unchanged")
+
+	again := WithOrigin(NewVariable(NewToken(Identifier, "x", nil, 7)), moved)
+	asrt.Equal(OriginOf(moved), OriginOf(again), "origins carry through repeated rewrites")
+
+	parent := NewBinary(again.(Expr), NewToken(Star, "*", nil, 7), NewLiteral(2.0, NewToken(Number, "2", 2.0, 7)))
+	asrt.Equal(Position{Line: 1, Column: 1, Offset: 0}, parent.Pos(), "parents' spans take in their children's origins")
+}
+
+func TestWithSpan(t *testing.T) {
+	asrt := assert.New(t)
+	sum := parseSource(t, "1 +\n2 +\n\n3")
+	negated := parseSource(t, "-\n(1 +\n2 +\n\n3)")
+
+	widened := WithSpan(sum, negated)
+	asrt.Equal(Origin{Pos: Position{Line: 1, Column: 1, Offset: 0}, End: Position{Line: 5, Column: 2, Offset: 13}, Line: 2}, OriginOf(widened), "the span grows but the line is the survivor's own")
+	asrt.Equal(Origin{Pos: Position{Line: 1, Column: 1, Offset: 0}, End: Position{Line: 4, Column: 2, Offset: 10}, Line: 2}, OriginOf(WithSpan(sum, NewLiteral(1.0, NewToken(Number, "1", 1.0, 2)))), "the span never shrinks")
+
+	block := parseProgram(t, "\n{\nprint 1;\n}")[0]
+	ifStmt := parseProgram(t, "if (true)\n{\nprint 1;\n}")[0]
+	asrt.Equal(Origin{Pos: Position{Line: 1, Column: 1, Offset: 0}, End: Position{Line: 4, Column: 2, Offset: 22}, Line: 2}, OriginOf(WithSpan(block, ifStmt)), "statements keep their own line too")
+}
@@ -0,0 +1,297 @@
+package lox
+
+import (
+	"errors"
+	"fmt"
+)
+
+// binding is where the resolver found a variable: in the slot of a local
+// scope depth environments out from the one in use, or, if global, in the
+// slot of the globals table.
+type binding struct {
+	global bool
+	depth  int
+	slot   int
+}
+
+// resolver binds each variable in a program to where it will be stored, so
+// that the interpreter can reach it by index instead of searching for it by
+// name. Each scope numbers its variables in the order they are declared,
+// which is the order the interpreter defines them in, so a variable's slot
+// is the same when the program runs as when it was resolved.
+//
+// A variable that isn't declared in any enclosing local scope is global,
+// and is given a slot in the globals table even if it hasn't been defined
+// yet; it may be defined by a later statement or from Go. Variables the
+// resolver never saw, such as those in expressions a debugger evaluates
+// while a program is paused, are still found by name.
+//
+// The resolver also interns the program's string constants in strings, for
+// the interpreter to reuse.
+type resolver struct {
+	globals *Environment
+	strings *stringTable
+	scopes  []map[string]local
+	errs    []error
+
+	// initializing is the name of the local variable whose initializer is
+	// being resolved, while that variable isn't yet declared in its scope.
+	initializing string
+
+	// resolution, if not nil, records declarations and uses for Resolve.
+	// globalDeclarations holds the first declaration of each global, and
+	// globalUses the uses of globals, which may come before it.
+	resolution         *Resolution
+	globalDeclarations map[string]int
+	globalUses         []int
+}
+
+// local is a variable declared in a local scope: its slot, and the index of
+// its declaration in the Resolution being recorded, if any.
+type local struct {
+	slot        int
+	declaration int
+}
+
+// Resolution is what Resolve found out about where a program's variables
+// are declared. Tools can match declarations and uses to the source by the
+// Offset of their tokens.
+type Resolution struct {
+	// Declarations are the names declared by the program's var statements,
+	// for-in loops and catch clauses.
+	Declarations []Token
+	// Uses are the variables the program reads or assigns.
+	Uses []Use
+}
+
+// Use is a variable read or assigned by a program. Declaration indexes the
+// Resolution's Declarations, and is -1 for a global the program never
+// declares, such as a built-in. Uses of a global refer to the first
+// declaration of it, wherever that is in the program.
+type Use struct {
+	Name        Token
+	Declaration int
+}
+
+// Resolve binds the variables in statements the way the interpreter does
+// before running them, and reports where each one is declared, along with
+// any errors that would stop the program from running, such as reading a
+// local variable in its own initializer.
+func Resolve(statements []Stmt) (Resolution, error) {
+	r := &resolver{
+		globals:            newTable(nil),
+		strings:            newStringTable(),
+		resolution:         &Resolution{},
+		globalDeclarations: map[string]int{},
+	}
+	r.stmts(statements)
+	for _, idx := range r.globalUses {
+		use := &r.resolution.Uses[idx]
+		if declaration, ok := r.globalDeclarations[use.Name.Lexeme]; ok {
+			use.Declaration = declaration
+		}
+	}
+	return *r.resolution, errors.Join(r.errs...)
+}
+
+// resolve returns a copy of statements with its variables bound, numbering
+// the slots of globals in the table globals. A program with errors must not
+// be run.
+func resolve(statements []Stmt, globals *Environment, strings *stringTable) ([]Stmt, error) {
+	r := &resolver{globals: globals, strings: strings}
+	resolved := r.stmts(statements)
+	return resolved, errors.Join(r.errs...)
+}
+
+// resolveExpr returns a copy of e with its variables bound. Expressions
+// don't declare variables, so they have nothing to report.
+func resolveExpr(e Expr, globals *Environment, strings *stringTable) Expr {
+	r := &resolver{globals: globals, strings: strings}
+	return r.expr(e)
+}
+
+func (r *resolver) stmts(statements []Stmt) []Stmt {
+	if statements == nil {
+		return nil
+	}
+	resolved := make([]Stmt, len(statements))
+	for idx, stmt := range statements {
+		resolved[idx] = r.stmt(stmt)
+	}
+	return resolved
+}
+
+func (r *resolver) stmt(s Stmt) Stmt {
+	if s == nil {
+		return nil
+	}
+	resolved, _ := AcceptStmt[Stmt](s, r)
+	return resolved
+}
+
+// expr binds the variables in e. Expressions don't declare variables, so
+// they can be rewritten in any order; uses are checked and recorded
+// separately, in source order.
+func (r *resolver) expr(e Expr) Expr {
+	if e == nil {
+		return nil
+	}
+	if r.initializing != "" || r.resolution != nil {
+		Inspect(e, func(n Node) bool {
+			switch n := n.(type) {
+			case Variable:
+				if n.name.Lexeme == r.initializing {
+					r.errs = append(r.errs, &Error{
+						Line:    n.name.Line,
+						Where:   fmt.Sprintf("at '%s'", n.name.Lexeme),
+						Message: "can't read local variable in its own initializer",
+						kind:    ErrLoxSyntax,
+					})
+				}
+				r.use(n.name)
+			case Assign:
+				r.use(n.name)
+			}
+			return true
+		})
+	}
+	return Rewrite(e, func(n Node) Node {
+		switch n := n.(type) {
+		case Variable:
+			n.binding = r.lookup(n.name.Lexeme)
+			return n
+		case Assign:
+			n.binding = r.lookup(n.name.Lexeme)
+			return n
+		case Literal:
+			if s, ok := n.literal.(string); ok {
+				n.literal = r.strings.intern(s)
+			}
+			return n
+		}
+		return n
+	}).(Expr)
+}
+
+// use records a use of name in the Resolution, if there is one.
+func (r *resolver) use(name Token) {
+	if r.resolution == nil {
+		return
+	}
+	use := Use{Name: name, Declaration: -1}
+	for depth := len(r.scopes) - 1; depth >= 0; depth-- {
+		if l, ok := r.scopes[depth][name.Lexeme]; ok {
+			use.Declaration = l.declaration
+			break
+		}
+	}
+	if use.Declaration < 0 {
+		r.globalUses = append(r.globalUses, len(r.resolution.Uses))
+	}
+	r.resolution.Uses = append(r.resolution.Uses, use)
+}
+
+// scoped resolves body in a new local scope that starts with the variables
+// named by declared, if any.
+func (r *resolver) scoped(body func(), declared ...Token) {
+	r.scopes = append(r.scopes, map[string]local{})
+	for _, name := range declared {
+		r.declare(name)
+	}
+	body()
+	r.scopes = r.scopes[:len(r.scopes)-1]
+}
+
+// declare gives name a slot in the innermost scope, or in the globals table
+// at the top level. Declaring a name again in the same scope reuses its slot.
+func (r *resolver) declare(name Token) *binding {
+	declaration := -1
+	if r.resolution != nil {
+		declaration = len(r.resolution.Declarations)
+		r.resolution.Declarations = append(r.resolution.Declarations, name)
+	}
+
+	if len(r.scopes) == 0 {
+		if _, ok := r.globalDeclarations[name.Lexeme]; !ok && declaration >= 0 {
+			r.globalDeclarations[name.Lexeme] = declaration
+		}
+		return &binding{global: true, slot: r.globals.reserve(name.Lexeme)}
+	}
+	scope := r.scopes[len(r.scopes)-1]
+	l, ok := scope[name.Lexeme]
+	if !ok {
+		l.slot = len(scope)
+	}
+	l.declaration = declaration
+	scope[name.Lexeme] = l
+	return &binding{slot: l.slot}
+}
+
+func (r *resolver) lookup(name string) *binding {
+	for depth := 0; depth < len(r.scopes); depth++ {
+		if l, ok := r.scopes[len(r.scopes)-1-depth][name]; ok {
+			return &binding{depth: depth, slot: l.slot}
+		}
+	}
+	return &binding{global: true, slot: r.globals.reserve(name)}
+}
+
+func (r *resolver) VisitExpressionStmt(s ExpressionStmt) (Stmt, error) {
+	s.expr = r.expr(s.expr)
+	return s, nil
+}
+
+func (r *resolver) VisitPrintStmt(s PrintStmt) (Stmt, error) {
+	s.expr = r.expr(s.expr)
+	return s, nil
+}
+
+func (r *resolver) VisitVarStmt(s VarStmt) (Stmt, error) {
+	// The initializer is resolved before the variable is declared. A local
+	// variable can't be read there, since it has no value yet, unless an
+	// earlier declaration in the same scope gave it one. A global read
+	// there has whatever value it already had.
+	if len(r.scopes) > 0 {
+		if _, ok := r.scopes[len(r.scopes)-1][s.name.Lexeme]; !ok {
+			r.initializing = s.name.Lexeme
+		}
+	}
+	s.initializer = r.expr(s.initializer)
+	r.initializing = ""
+	s.binding = r.declare(s.name)
+	return s, nil
+}
+
+func (r *resolver) VisitBlockStmt(s BlockStmt) (Stmt, error) {
+	r.scoped(func() { s.statements = r.stmts(s.statements) })
+	return s, nil
+}
+
+func (r *resolver) VisitForInStmt(s ForInStmt) (Stmt, error) {
+	s.iterable = r.expr(s.iterable)
+	r.scoped(func() { s.body = r.stmt(s.body) }, s.name)
+	return s, nil
+}
+
+func (r *resolver) VisitIfStmt(s IfStmt) (Stmt, error) {
+	s.condition = r.expr(s.condition)
+	s.thenBranch = r.stmt(s.thenBranch)
+	s.elseBranch = r.stmt(s.elseBranch)
+	return s, nil
+}
+
+func (r *resolver) VisitThrowStmt(s ThrowStmt) (Stmt, error) {
+	s.value = r.expr(s.value)
+	return s, nil
+}
+
+func (r *resolver) VisitTryStmt(s TryStmt) (Stmt, error) {
+	r.scoped(func() { s.body = r.stmts(s.body) })
+	if s.catchBody != nil {
+		r.scoped(func() { s.catchBody = r.stmts(s.catchBody) }, s.catchName)
+	}
+	if s.finallyBody != nil {
+		r.scoped(func() { s.finallyBody = r.stmts(s.finallyBody) })
+	}
+	return s, nil
+}
@@ -0,0 +1,228 @@
+// ABOUTME: Tests for binding variables to environment slots before a program runs
+// ABOUTME: Checks the slots chosen, the errors and declarations reported, that resolved programs behave as before, and benchmarks lookups
+package lox
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// bindings lists where each variable use and declaration in statements is
+// bound, in source order.
+func bindings(statements []Stmt) []string {
+	var found []string
+	describe := func(name string, b *binding) {
+		switch {
+		case b == nil:
+			found = append(found, name+" unbound")
+		case b.global:
+			found = append(found, fmt.Sprintf("%s global", name))
+		default:
+			found = append(found, fmt.Sprintf("%s %d:%d", name, b.depth, b.slot))
+		}
+	}
+	for _, stmt := range statements {
+		Inspect(stmt, func(n Node) bool {
+			switch n := n.(type) {
+			case Variable:
+				describe(n.name.Lexeme, n.binding)
+			case Assign:
+				describe(n.name.Lexeme, n.binding)
+			case VarStmt:
+				describe(n.name.Lexeme, n.binding)
+			}
+			return true
+		})
+	}
+	return found
+}
+
+func TestResolver(t *testing.T) {
+	tests := []struct {
+		name     string
+		source   string
+		expected []string
+	}{
+		{name: "globals", source: "var a = 1; print a + b;", expected: []string{"a global", "a global", "b global"}},
+		{name: "locals", source: "{ var a; var b; print b; a = b; }", expected: []string{"a 0:0", "b 0:1", "b 0:1", "a 0:0", "b 0:1"}},
+		{name: "enclosing scope", source: "{ var a; { var b; print a; } }", expected: []string{"a 0:0", "b 0:0", "a 1:0"}},
+		{name: "shadowing", source: "var a; { print a; var a = 1; print a; }", expected: []string{"a global", "a global", "a 0:0", "a 0:0"}},
+		{name: "global initialized from itself", source: "var a = a;", expected: []string{"a global", "a global"}},
+		{name: "redeclaration", source: "{ var a; var b; var a; }", expected: []string{"a 0:0", "b 0:1", "a 0:0"}},
+		{name: "loop variable", source: "for x in xs { var y = x; }", expected: []string{"xs global", "y 0:0", "x 1:0"}},
+		{name: "loop body without block", source: "for x in xs print x;", expected: []string{"xs global", "x 0:0"}},
+		{name: "catch variable", source: "try { var a; } catch (e) { var b = e; } finally { var c; }", expected: []string{"a 0:0", "b 0:1", "e 0:0", "c 0:0"}},
+		{name: "if branches share the scope", source: "{ var a; if (a) print a; else { print a; } }", expected: []string{"a 0:0", "a 0:0", "a 0:0", "a 1:0"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resolved, err := resolve(parseProgram(t, tt.source), newTable(nil), newStringTable())
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, bindings(resolved))
+		})
+	}
+}
+
+func TestResolver_LeavesOriginalUnchanged(t *testing.T) {
+	statements := parseProgram(t, "var a; { var b = a; }")
+	_, err := resolve(statements, newTable(nil), newStringTable())
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a unbound", "b unbound", "a unbound"}, bindings(statements))
+}
+
+func TestResolver_Errors(t *testing.T) {
+	tests := []struct {
+		name     string
+		source   string
+		expected []string
+	}{
+		{name: "own initializer", source: "{ var a = a; }", expected: []string{"[line 1] syntax error at 'a': can't read local variable in its own initializer"}},
+		{name: "own initializer shadowing a global", source: "var a = 1;\n{\n  var a = [a];\n}", expected: []string{"[line 3] syntax error at 'a': can't read local variable in its own initializer"}},
+		{name: "own initializer in a loop", source: "for x in [1] { var x = x + 1; }", expected: []string{"[line 1] syntax error at 'x': can't read local variable in its own initializer"}},
+		{name: "every error", source: "{ var a = a; var b = b; }", expected: []string{
+			"[line 1] syntax error at 'a': can't read local variable in its own initializer",
+			"[line 1] syntax error at 'b': can't read local variable in its own initializer",
+		}},
+		{name: "redeclaration reads the earlier value", source: "{ var a = 1; var a = a + 1; }"},
+		{name: "global", source: "var a = a;"},
+		{name: "assignment", source: "{ var a = 1; { var b = a = 2; } }"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			asrt := assert.New(t)
+			statements := parseProgram(t, tt.source)
+			var messages []string
+			_, err := resolve(statements, newTable(nil), newStringTable())
+			for _, e := range Errors(err) {
+				asrt.ErrorIs(e, ErrLoxSyntax)
+				messages = append(messages, e.Error())
+			}
+			asrt.Equal(tt.expected, messages)
+
+			var out bytes.Buffer
+			_, err = NewInterpreter(WithStdout(&out)).Execute(append(statements, parseProgram(t, `print "ran";`)...))
+			if tt.expected != nil {
+				asrt.ErrorIs(err, ErrLoxSyntax)
+				asrt.Empty(out.String(), "programs with errors don't run")
+			} else {
+				asrt.NotContains(fmt.Sprint(err), "syntax error")
+			}
+		})
+	}
+}
+
+func TestResolve(t *testing.T) {
+	asrt := assert.New(t)
+	source := "print a;\nvar a = len([]);\n{ var b = a; { var b = b; } b = 2; }\nfor x in [a] { print x; }\ntry {} catch (e) { print e; }\nvar a;"
+	resolution, err := Resolve(parseProgram(t, source))
+	asrt.Error(err, "b is read in its own initializer")
+
+	var declarations []string
+	for _, d := range resolution.Declarations {
+		declarations = append(declarations, fmt.Sprintf("%s@%d", d.Lexeme, d.Line))
+	}
+	asrt.Equal([]string{"a@2", "b@3", "b@3", "x@4", "e@5", "a@6"}, declarations)
+
+	var uses []string
+	for _, u := range resolution.Uses {
+		uses = append(uses, fmt.Sprintf("%s@%d->%d", u.Name.Lexeme, u.Name.Line, u.Declaration))
+	}
+	asrt.Equal([]string{
+		"a@1->0",
+		"len@2->-1",
+		"a@3->0", "b@3->1", "b@3->1",
+		"a@4->0", "x@4->3",
+		"e@5->4",
+	}, uses)
+}
+
+func TestResolver_GlobalSlots(t *testing.T) {
+	asrt := assert.New(t)
+	var out bytes.Buffer
+	interp := NewInterpreter(WithStdout(&out))
+
+	_, err := interp.Execute(parseProgram(t, "print later;"))
+	asrt.EqualError(err, "[line 1] runtime error: undefined variable 'later'")
+	asrt.Empty(interp.Globals().Names(), "a numbered but undefined global isn't listed")
+
+	_, err = interp.Execute(parseProgram(t, `var later = "now"; print later; print len(later);`))
+	asrt.NoError(err)
+	asrt.Equal("now\n3\n", out.String())
+	asrt.Equal([]string{"later"}, interp.Globals().Names())
+
+	value, ok := interp.Globals().Lookup("later")
+	asrt.True(ok)
+	asrt.Equal("now", value)
+}
+
+func TestResolver_PreservesBehavior(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		output string
+	}{
+		{name: "shadowing", source: `var a = "global"; { print a; var a = "local"; print a; } print a;`, output: "global\nlocal\nglobal\n"},
+		{name: "global initialized from itself", source: `var a = 1; var a = a + 1; print a;`, output: "2\n"},
+		{name: "assignment reaches enclosing scope", source: "var n = 0; for x in [1, 2, 3] { { n = n + x; } } print n;", output: "6\n"},
+		{name: "redeclaration", source: "{ var a = 1; var a = a + 1; print a; }", output: "2\n"},
+		{name: "fresh scope each iteration", source: "for x in [1, 2] { var y; print y; y = x; }", output: "nil\nnil\n"},
+		{name: "shadowed built-in", source: "{ var len = 2; print len; } print len([1]);", output: "2\n1\n"},
+		{name: "assigned built-in", source: "len = 3; print len;", output: "3\n"},
+		{name: "catch variable", source: `try { throw "a"; } catch (e) { var f = e + "b"; print f; }`, output: "ab\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			asrt := assert.New(t)
+			statements := parseProgram(t, tt.source)
+
+			var resolved bytes.Buffer
+			_, err := NewInterpreter(WithStdout(&resolved)).Execute(statements)
+			asrt.NoError(err)
+			asrt.Equal(tt.output, resolved.String())
+
+			var byName bytes.Buffer
+			_, err = runByName(NewInterpreter(WithStdout(&byName)), statements)
+			asrt.NoError(err)
+			asrt.Equal(tt.output, byName.String())
+		})
+	}
+}
+
+// runByName runs statements without resolving them, from a local scope
+// inside interp's globals, so that each variable is found by comparing its
+// name with those of one scope after another, as it was before variables
+// had slots.
+func runByName(interp *Interpreter, statements []Stmt) (Value, error) {
+	interp.environment = NewEnvironment(interp.globals)
+	return interp.Execute(statements)
+}
+
+// BenchmarkFib compares finding variables in slots bound by the resolver
+// with the baseline of looking their names up in each scope in turn.
+func BenchmarkFib(b *testing.B) {
+	tokens, err := NewScanner(fibSource(100)).ScanTokens()
+	assert.NoError(b, err)
+	statements, err := NewParser(tokens).ParseProgram()
+	assert.NoError(b, err)
+
+	b.Run("slots", func(b *testing.B) {
+		for b.Loop() {
+			result, err := NewInterpreter().Execute(statements)
+			assert.NoError(b, err)
+			assert.Equal(b, 832040.0, result)
+		}
+	})
+	b.Run("names", func(b *testing.B) {
+		for b.Loop() {
+			result, err := runByName(NewInterpreter(), statements)
+			assert.NoError(b, err)
+			assert.Equal(b, 832040.0, result)
+		}
+	})
+}
@@ -0,0 +1,54 @@
+package lox
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+)
+
+// RunScript runs a script the way the glox command does. A script of Lox
+// source is scanned, parsed and optimized before it runs; a program compiled
+// with WriteCompiled runs as it is. The program writes to stdout and stderr,
+// and if it ends with an expression statement, the value is printed to
+// stdout after it.
+//
+// The error returned, if any, unwraps to ErrLoxSyntax when the script didn't
+// run, to ErrLoxRuntime when it stopped early, and to one of the compiled
+// program errors when a compiled script couldn't be read.
+func RunScript(script []byte, stdout, stderr io.Writer, opts ...Option) error {
+	opts = append([]Option{WithStdout(stdout), WithStderr(stderr)}, opts...)
+	return NewInterpreter(opts...).RunScript(script)
+}
+
+// RunScript runs a script with i as the package's RunScript does, printing
+// the value of a final expression statement to i's stdout. Globals the
+// script defines stay defined for the next script i runs, so a REPL can run
+// each line it reads with the same Interpreter.
+func (i *Interpreter) RunScript(script []byte) error {
+	var statements []Stmt
+	if IsCompiled(script) {
+		var err error
+		if statements, err = ReadCompiled(bytes.NewReader(script)); err != nil {
+			return err
+		}
+	} else {
+		tokens, err := NewScanner(string(script)).ScanTokens()
+		if err != nil {
+			return err
+		}
+		parsed, err := NewParser(tokens).ParseProgram()
+		if err != nil {
+			return err
+		}
+		statements = Optimize(parsed)
+	}
+
+	result, err := i.Execute(statements)
+	if err != nil {
+		return err
+	}
+	if result != nil {
+		fmt.Fprintln(i.stdout, Stringify(result))
+	}
+	return nil
+}
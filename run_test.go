@@ -0,0 +1,62 @@
+// ABOUTME: Tests for running a script the way the glox command does
+// ABOUTME: Covers source and compiled scripts, the printed result, the errors returned and reusing an Interpreter
+package lox
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunScript(t *testing.T) {
+	tests := []struct {
+		name    string
+		script  func(t *testing.T) []byte
+		output  string
+		wantErr error
+	}{
+		{name: "source", script: sourceScript(`print "a"; 1 + 2;`), output: "a\n3\n"},
+		{name: "no result", script: sourceScript(`var a = 1;`), output: ""},
+		{name: "compiled", script: func(t *testing.T) []byte { return compile(t, parseProgram(t, `print "a"; 1 + 2;`)) }, output: "a\n3\n"},
+		{name: "syntax error", script: sourceScript(`print "a"; print;`), wantErr: ErrLoxSyntax},
+		{name: "runtime error", script: sourceScript(`print "a"; -"b";`), output: "a\n", wantErr: ErrLoxRuntime},
+		{name: "corrupt compiled program", script: func(t *testing.T) []byte {
+			compiled := compile(t, parseProgram(t, `print "a";`))
+			compiled[len(compiled)-1]++
+			return compiled
+		}, wantErr: ErrCompiledCorrupt},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			asrt := assert.New(t)
+			var out bytes.Buffer
+			err := RunScript(tt.script(t), &out, io.Discard)
+			if tt.wantErr != nil {
+				asrt.ErrorIs(err, tt.wantErr)
+			} else {
+				asrt.NoError(err)
+			}
+			asrt.Equal(tt.output, out.String())
+		})
+	}
+}
+
+func sourceScript(s string) func(*testing.T) []byte {
+	return func(*testing.T) []byte { return []byte(s) }
+}
+
+func TestInterpreter_RunScriptKeepsGlobals(t *testing.T) {
+	asrt := assert.New(t)
+	var out bytes.Buffer
+	interpreter := NewInterpreter(WithStdout(&out))
+
+	asrt.NoError(interpreter.RunScript([]byte("var a = 1;")))
+	asrt.NoError(interpreter.RunScript([]byte("print a;")))
+	asrt.ErrorIs(interpreter.RunScript([]byte("print b;")), ErrLoxRuntime)
+	asrt.ErrorIs(interpreter.RunScript([]byte("print ;")), ErrLoxSyntax)
+	asrt.NoError(interpreter.RunScript([]byte("a = a + 1;\na")))
+	asrt.Equal("1\n2\n", out.String())
+}
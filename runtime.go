@@ -1,8 +1,83 @@
 package lox
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
 
 var (
 	ErrLoxSyntax  = errors.New("syntax error")
 	ErrLoxRuntime = errors.New("runtime error")
 )
+
+// Error is a syntax or runtime error tied to the source line it occurred on.
+// It unwraps to ErrLoxSyntax or ErrLoxRuntime. Runtime errors also carry a
+// Trace of the calls that were in progress, which StackTrace formats.
+type Error struct {
+	Line    int
+	Where   string
+	Message string
+	Trace   []TraceEntry
+	kind    error
+}
+
+// TraceEntry is one level of a Lox stack trace: the function that was running
+// and the line it had reached. The outermost entry is the script itself.
+type TraceEntry struct {
+	Function string
+	Line     int
+}
+
+func (e *Error) Error() string {
+	where := ""
+	if e.Where != "" {
+		where = " " + e.Where
+	}
+	return fmt.Sprintf("[line %d] %s%s: %s", e.Line, e.kind, where, e.Message)
+}
+
+// StackTrace formats the trace of the first runtime error in err, one call per
+// line, innermost first. It returns "" if err has no trace.
+func StackTrace(err error) string {
+	for _, e := range Errors(err) {
+		if len(e.Trace) == 0 {
+			continue
+		}
+		var trace strings.Builder
+		for _, entry := range e.Trace {
+			fmt.Fprintf(&trace, "    at %s (line %d)\n", entry.Function, entry.Line)
+		}
+		return trace.String()
+	}
+	return ""
+}
+
+func (e *Error) Unwrap() error {
+	return e.kind
+}
+
+// Errors flattens an error returned by the scanner, parser or interpreter into
+// the individual *Error values it carries.
+func Errors(err error) []*Error {
+	var errs []*Error
+	var walk func(error)
+	walk = func(err error) {
+		switch e := err.(type) {
+		case nil:
+		case *Error:
+			errs = append(errs, e)
+		case interface{ Unwrap() []error }:
+			for _, inner := range e.Unwrap() {
+				walk(inner)
+			}
+		default:
+			var loxErr *Error
+			if errors.As(err, &loxErr) {
+				errs = append(errs, loxErr)
+			}
+		}
+	}
+	walk(err)
+	return errs
+}
@@ -2,7 +2,6 @@ package lox
 
 import (
 	"errors"
-	"fmt"
 	"strconv"
 )
 
@@ -11,26 +10,33 @@ type Scanner struct {
 	Tokens         []Token
 	start, current int
 	line           int
-	errors         []error
+	// lineStart is the offset of the first byte of the current line, and
+	// column the column the token being scanned starts at.
+	lineStart int
+	column    int
+	errors    []error
+	strings   *stringTable
 }
 
 func NewScanner(source string) *Scanner {
 	return &Scanner{
-		source: source,
-		line:   1,
+		source:  source,
+		line:    1,
+		strings: newStringTable(),
 	}
 }
 
 func (s *Scanner) ScanTokens() ([]Token, error) {
 	for !s.isAtEnd() {
-		s.start = s.current
+		s.begin()
 		err := s.scanToken()
 		if err != nil {
 			s.errors = append(s.errors, err)
 		}
 	}
 
-	s.Tokens = append(s.Tokens, NewToken(EOF, "", nil, s.line))
+	s.begin()
+	s.Tokens = append(s.Tokens, s.newToken(EOF, "", nil))
 
 	if len(s.errors) > 0 {
 		return s.Tokens, errors.Join(s.errors...)
@@ -49,8 +55,14 @@ func (s *Scanner) scanToken() error {
 		s.addToken(LeftBrace)
 	case '}':
 		s.addToken(RightBrace)
+	case '[':
+		s.addToken(LeftBracket)
+	case ']':
+		s.addToken(RightBracket)
 	case ',':
 		s.addToken(Comma)
+	case ':':
+		s.addToken(Colon)
 	case '.':
 		s.addToken(Dot)
 	case '-':
@@ -93,7 +105,7 @@ func (s *Scanner) scanToken() error {
 		}
 	case ' ', '\r', '\t':
 	case '\n':
-		s.line++
+		s.newLine()
 	case '"':
 		return s.handleString()
 	default:
@@ -107,14 +119,32 @@ func (s *Scanner) scanToken() error {
 	return nil
 }
 
+// begin starts a token at the current offset.
+func (s *Scanner) begin() {
+	s.start = s.current
+	s.column = s.current - s.lineStart + 1
+}
+
+// newLine records that the byte just consumed ended a line.
+func (s *Scanner) newLine() {
+	s.line++
+	s.lineStart = s.current
+}
+
+func (s *Scanner) newToken(tokenType TokenType, lexeme string, literal any) Token {
+	token := NewToken(tokenType, lexeme, literal, s.line)
+	token.Offset, token.Column = s.start, s.column
+	return token
+}
+
 func (s *Scanner) addToken(tokenType TokenType) {
 	lexeme := s.source[s.start:s.current]
-	s.Tokens = append(s.Tokens, NewToken(tokenType, lexeme, nil, s.line))
+	s.Tokens = append(s.Tokens, s.newToken(tokenType, lexeme, nil))
 }
 
 func (s *Scanner) addTokenWithLiteral(tokenType TokenType, literal any) {
 	lexeme := s.source[s.start:s.current]
-	s.Tokens = append(s.Tokens, NewToken(tokenType, lexeme, literal, s.line))
+	s.Tokens = append(s.Tokens, s.newToken(tokenType, lexeme, literal))
 }
 
 func (s *Scanner) advance() rune {
@@ -156,10 +186,9 @@ func (s *Scanner) handleComment() {
 
 func (s *Scanner) handleString() error {
 	for s.peek() != '"' && !s.isAtEnd() {
-		if s.peek() == '\n' {
-			s.line++
+		if s.advance() == '\n' {
+			s.newLine()
 		}
-		s.advance()
 	}
 
 	if s.isAtEnd() {
@@ -168,7 +197,7 @@ func (s *Scanner) handleString() error {
 
 	s.advance()
 
-	value := s.source[s.start+1 : s.current-1]
+	value := s.strings.intern(s.source[s.start+1 : s.current-1])
 	s.addTokenWithLiteral(String, value)
 	return nil
 }
@@ -200,11 +229,11 @@ func (s *Scanner) handleIdentifier() error {
 	}
 
 	lexeme := s.source[s.start:s.current]
-	tokenType := Identifier
-	if tt, ok := keywords[lexeme]; ok {
-		tokenType = tt
+	if tokenType, ok := keywords[lexeme]; ok {
+		s.addToken(tokenType)
+		return nil
 	}
-	s.addToken(tokenType)
+	s.Tokens = append(s.Tokens, s.newToken(Identifier, s.strings.intern(lexeme), nil))
 	return nil
 }
 
@@ -213,7 +242,7 @@ func (s *Scanner) isAtEnd() bool {
 }
 
 func (s *Scanner) reportError(msg string) error {
-	return fmt.Errorf("[line %d] %w: %s", s.line, ErrLoxSyntax, msg)
+	return &Error{Line: s.line, Message: msg, kind: ErrLoxSyntax}
 }
 
 func isDigit(r rune) bool {
@@ -71,6 +71,42 @@ func TestScanTokens(t *testing.T) {
 				NewToken(EOF, "", nil, 1),
 			},
 		},
+		{
+			name:   "colon",
+			source: ":",
+			expected: []Token{
+				NewToken(Colon, ":", nil, 1),
+				NewToken(EOF, "", nil, 1),
+			},
+		},
+		{
+			name:   "left bracket",
+			source: "[",
+			expected: []Token{
+				NewToken(LeftBracket, "[", nil, 1),
+				NewToken(EOF, "", nil, 1),
+			},
+		},
+		{
+			name:   "right bracket",
+			source: "]",
+			expected: []Token{
+				NewToken(RightBracket, "]", nil, 1),
+				NewToken(EOF, "", nil, 1),
+			},
+		},
+		{
+			name:   "list literal",
+			source: "[1, 2]",
+			expected: []Token{
+				NewToken(LeftBracket, "[", nil, 1),
+				NewToken(Number, "1", 1.0, 1),
+				NewToken(Comma, ",", nil, 1),
+				NewToken(Number, "2", 2.0, 1),
+				NewToken(RightBracket, "]", nil, 1),
+				NewToken(EOF, "", nil, 1),
+			},
+		},
 		{
 			name:   "comma",
 			source: ",",
@@ -977,6 +1013,46 @@ func TestScanTokens(t *testing.T) {
 				NewToken(EOF, "", nil, 1),
 			},
 		},
+		{
+			name:   "reserved word: in",
+			source: "in",
+			expected: []Token{
+				NewToken(In, "in", nil, 1),
+				NewToken(EOF, "", nil, 1),
+			},
+		},
+		{
+			name:   "reserved word: try",
+			source: "try",
+			expected: []Token{
+				NewToken(Try, "try", nil, 1),
+				NewToken(EOF, "", nil, 1),
+			},
+		},
+		{
+			name:   "reserved word: catch",
+			source: "catch",
+			expected: []Token{
+				NewToken(Catch, "catch", nil, 1),
+				NewToken(EOF, "", nil, 1),
+			},
+		},
+		{
+			name:   "reserved word: finally",
+			source: "finally",
+			expected: []Token{
+				NewToken(Finally, "finally", nil, 1),
+				NewToken(EOF, "", nil, 1),
+			},
+		},
+		{
+			name:   "reserved word: throw",
+			source: "throw",
+			expected: []Token{
+				NewToken(Throw, "throw", nil, 1),
+				NewToken(EOF, "", nil, 1),
+			},
+		},
 		{
 			name:   "reserved word: while",
 			source: "while",
@@ -1224,11 +1300,72 @@ func TestScanTokens(t *testing.T) {
 			tokens, err := scanner.ScanTokens()
 
 			asrt.NoError(err)
+			// Where each token is has its own test below.
+			for idx := range tokens {
+				tokens[idx].Offset, tokens[idx].Column = 0, 0
+			}
 			asrt.Equal(tt.expected, tokens)
 		})
 	}
 }
 
+func TestScanTokens_Positions(t *testing.T) {
+	tests := []struct {
+		name     string
+		source   string
+		expected []Position
+	}{
+		{name: "empty source", source: "", expected: []Position{{Line: 1, Column: 1, Offset: 0}}},
+		{
+			name:   "one line",
+			source: "var x = 10;",
+			expected: []Position{
+				{Line: 1, Column: 1, Offset: 0},
+				{Line: 1, Column: 5, Offset: 4},
+				{Line: 1, Column: 7, Offset: 6},
+				{Line: 1, Column: 9, Offset: 8},
+				{Line: 1, Column: 11, Offset: 10},
+				{Line: 1, Column: 12, Offset: 11},
+			},
+		},
+		{
+			name:   "columns restart on each line",
+			source: "a\n  bb // c\n\tc",
+			expected: []Position{
+				{Line: 1, Column: 1, Offset: 0},
+				{Line: 2, Column: 3, Offset: 4},
+				{Line: 3, Column: 2, Offset: 13},
+				{Line: 3, Column: 3, Offset: 14},
+			},
+		},
+		{
+			name:   "after a multi-line string",
+			source: "\"a\nbc\" <=\n x",
+			expected: []Position{
+				{Line: 1, Column: 1, Offset: 0},
+				{Line: 2, Column: 5, Offset: 7},
+				{Line: 3, Column: 2, Offset: 11},
+				{Line: 3, Column: 3, Offset: 12},
+			},
+		},
+		{name: "two-character operator", source: "!= !", expected: []Position{{Line: 1, Column: 1, Offset: 0}, {Line: 1, Column: 4, Offset: 3}, {Line: 1, Column: 5, Offset: 4}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			asrt := assert.New(t)
+			tokens, err := NewScanner(tt.source).ScanTokens()
+			asrt.NoError(err)
+
+			positions := make([]Position, len(tokens))
+			for idx, token := range tokens {
+				positions[idx] = tokenPos(token)
+			}
+			asrt.Equal(tt.expected, positions)
+		})
+	}
+}
+
 func TestScanTokens_InvalidCharacters(t *testing.T) {
 	tests := []struct {
 		name             string
@@ -0,0 +1,338 @@
+package lox
+
+import (
+	"fmt"
+	"slices"
+)
+
+// StmtVisitor is implemented by code that walks statements and computes a
+// value of type R for each one. Use AcceptStmt to dispatch to it.
+type StmtVisitor[R any] interface {
+	VisitExpressionStmt(s ExpressionStmt) (R, error)
+	VisitPrintStmt(s PrintStmt) (R, error)
+	VisitVarStmt(s VarStmt) (R, error)
+	VisitBlockStmt(s BlockStmt) (R, error)
+	VisitForInStmt(s ForInStmt) (R, error)
+	VisitIfStmt(s IfStmt) (R, error)
+	VisitThrowStmt(s ThrowStmt) (R, error)
+	VisitTryStmt(s TryStmt) (R, error)
+}
+
+// Stmt is a statement node. The statement types in this package are the only
+// implementations.
+type Stmt interface {
+	Node
+	stmt()
+}
+
+// AcceptStmt calls the method of v that matches s's node type.
+func AcceptStmt[R any](s Stmt, v StmtVisitor[R]) (R, error) {
+	switch s := s.(type) {
+	case ExpressionStmt:
+		return v.VisitExpressionStmt(s)
+	case PrintStmt:
+		return v.VisitPrintStmt(s)
+	case VarStmt:
+		return v.VisitVarStmt(s)
+	case BlockStmt:
+		return v.VisitBlockStmt(s)
+	case ForInStmt:
+		return v.VisitForInStmt(s)
+	case IfStmt:
+		return v.VisitIfStmt(s)
+	case ThrowStmt:
+		return v.VisitThrowStmt(s)
+	case TryStmt:
+		return v.VisitTryStmt(s)
+	}
+	var zero R
+	return zero, fmt.Errorf("unknown statement type %T", s)
+}
+
+type ExpressionStmt struct {
+	expr Expr
+	origin
+}
+
+func NewExpressionStmt(expr Expr) ExpressionStmt {
+	return ExpressionStmt{expr: expr}
+}
+
+func (s ExpressionStmt) Expression() Expr {
+	return s.expr
+}
+
+func (s ExpressionStmt) Pos() Position {
+	return s.startOr(s.expr.Pos())
+}
+
+func (s ExpressionStmt) End() Position {
+	return s.endOr(s.expr.End())
+}
+
+func (ExpressionStmt) stmt() {}
+
+type PrintStmt struct {
+	keyword Token
+	expr    Expr
+	origin
+}
+
+func NewPrintStmt(keyword Token, expr Expr) PrintStmt {
+	return PrintStmt{keyword: keyword, expr: expr}
+}
+
+func (s PrintStmt) Keyword() Token {
+	return s.keyword
+}
+
+func (s PrintStmt) Expression() Expr {
+	return s.expr
+}
+
+func (s PrintStmt) Pos() Position {
+	return s.startOr(tokenPos(s.keyword))
+}
+
+func (s PrintStmt) End() Position {
+	return s.endOr(s.expr.End())
+}
+
+func (PrintStmt) stmt() {}
+
+type VarStmt struct {
+	keyword     Token
+	name        Token
+	initializer Expr
+	binding     *binding
+	origin
+}
+
+// NewVarStmt builds a variable declaration. initializer may be nil.
+func NewVarStmt(keyword, name Token, initializer Expr) VarStmt {
+	return VarStmt{keyword: keyword, name: name, initializer: initializer}
+}
+
+func (s VarStmt) Keyword() Token {
+	return s.keyword
+}
+
+func (s VarStmt) Name() Token {
+	return s.name
+}
+
+// Initializer is the variable's initial value, or nil if it has none.
+func (s VarStmt) Initializer() Expr {
+	return s.initializer
+}
+
+func (s VarStmt) Pos() Position {
+	return s.startOr(tokenPos(s.keyword))
+}
+
+func (s VarStmt) End() Position {
+	if s.initializer == nil {
+		return s.endOr(tokenEnd(s.name))
+	}
+	return s.endOr(s.initializer.End())
+}
+
+func (VarStmt) stmt() {}
+
+type BlockStmt struct {
+	brace      Token
+	statements []Stmt
+	closing    Token
+	origin
+}
+
+func NewBlockStmt(brace Token, statements []Stmt, closing Token) BlockStmt {
+	return BlockStmt{brace: brace, statements: statements, closing: closing}
+}
+
+func (s BlockStmt) Statements() []Stmt {
+	return slices.Clone(s.statements)
+}
+
+func (s BlockStmt) Pos() Position {
+	return s.startOr(tokenPos(s.brace))
+}
+
+func (s BlockStmt) End() Position {
+	return s.endOr(tokenEnd(s.closing))
+}
+
+func (BlockStmt) stmt() {}
+
+// ForInStmt runs body once for each element of iterable, with the element
+// bound to name in a fresh scope.
+type ForInStmt struct {
+	keyword  Token
+	name     Token
+	iterable Expr
+	body     Stmt
+	origin
+}
+
+func NewForInStmt(keyword, name Token, iterable Expr, body Stmt) ForInStmt {
+	return ForInStmt{keyword: keyword, name: name, iterable: iterable, body: body}
+}
+
+func (s ForInStmt) Keyword() Token {
+	return s.keyword
+}
+
+func (s ForInStmt) Name() Token {
+	return s.name
+}
+
+func (s ForInStmt) Iterable() Expr {
+	return s.iterable
+}
+
+func (s ForInStmt) Body() Stmt {
+	return s.body
+}
+
+func (s ForInStmt) Pos() Position {
+	return s.startOr(tokenPos(s.keyword))
+}
+
+func (s ForInStmt) End() Position {
+	return s.endOr(s.body.End())
+}
+
+func (ForInStmt) stmt() {}
+
+// IfStmt runs thenBranch if condition is truthy and elseBranch, which may be
+// nil, otherwise.
+type IfStmt struct {
+	keyword    Token
+	condition  Expr
+	thenBranch Stmt
+	elseBranch Stmt
+	origin
+}
+
+// NewIfStmt builds an if statement. elseBranch may be nil.
+func NewIfStmt(keyword Token, condition Expr, thenBranch, elseBranch Stmt) IfStmt {
+	return IfStmt{keyword: keyword, condition: condition, thenBranch: thenBranch, elseBranch: elseBranch}
+}
+
+func (s IfStmt) Keyword() Token {
+	return s.keyword
+}
+
+func (s IfStmt) Condition() Expr {
+	return s.condition
+}
+
+func (s IfStmt) Then() Stmt {
+	return s.thenBranch
+}
+
+// Else is nil if the statement has no else branch.
+func (s IfStmt) Else() Stmt {
+	return s.elseBranch
+}
+
+func (s IfStmt) Pos() Position {
+	return s.startOr(tokenPos(s.keyword))
+}
+
+func (s IfStmt) End() Position {
+	if s.elseBranch == nil {
+		return s.endOr(s.thenBranch.End())
+	}
+	return s.endOr(s.elseBranch.End())
+}
+
+func (IfStmt) stmt() {}
+
+type ThrowStmt struct {
+	keyword Token
+	value   Expr
+	origin
+}
+
+func NewThrowStmt(keyword Token, value Expr) ThrowStmt {
+	return ThrowStmt{keyword: keyword, value: value}
+}
+
+func (s ThrowStmt) Keyword() Token {
+	return s.keyword
+}
+
+func (s ThrowStmt) Value() Expr {
+	return s.value
+}
+
+func (s ThrowStmt) Pos() Position {
+	return s.startOr(tokenPos(s.keyword))
+}
+
+func (s ThrowStmt) End() Position {
+	return s.endOr(s.value.End())
+}
+
+func (ThrowStmt) stmt() {}
+
+// TryStmt runs body, handing anything thrown from it to catchBody with the
+// thrown value bound to catchName. finallyBody runs afterwards whether or not
+// anything was thrown. Either clause may be missing, but not both. closing is
+// the brace that ends the statement.
+type TryStmt struct {
+	keyword     Token
+	body        []Stmt
+	catchName   Token
+	catchBody   []Stmt
+	finallyBody []Stmt
+	closing     Token
+	origin
+}
+
+// NewTryStmt builds a try statement. A nil catchBody or finallyBody leaves
+// that clause out; catchName is ignored without a catch clause. It panics if
+// both are nil.
+func NewTryStmt(keyword Token, body []Stmt, catchName Token, catchBody, finallyBody []Stmt, closing Token) TryStmt {
+	if catchBody == nil && finallyBody == nil {
+		panic("lox: try statement without a catch or finally clause")
+	}
+	stmt := TryStmt{keyword: keyword, body: body, catchBody: catchBody, finallyBody: finallyBody, closing: closing}
+	if catchBody != nil {
+		stmt.catchName = catchName
+	}
+	return stmt
+}
+
+func (s TryStmt) Keyword() Token {
+	return s.keyword
+}
+
+func (s TryStmt) Body() []Stmt {
+	return slices.Clone(s.body)
+}
+
+func (s TryStmt) CatchName() Token {
+	return s.catchName
+}
+
+// CatchBody is nil if the statement has no catch clause.
+func (s TryStmt) CatchBody() []Stmt {
+	return slices.Clone(s.catchBody)
+}
+
+// FinallyBody is nil if the statement has no finally clause.
+func (s TryStmt) FinallyBody() []Stmt {
+	return slices.Clone(s.finallyBody)
+}
+
+func (s TryStmt) Pos() Position {
+	return s.startOr(tokenPos(s.keyword))
+}
+
+func (s TryStmt) End() Position {
+	return s.endOr(tokenEnd(s.closing))
+}
+
+func (TryStmt) stmt() {}
@@ -0,0 +1,254 @@
+package lox
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// defineStdlib adds the built-in native functions to env.
+func defineStdlib(env *Environment) {
+	for _, fn := range []*NativeFunction{
+		native("clock", 0, stdClock),
+		native("len", 1, stdLen),
+		native("str", 1, stdStr),
+		native("num", 1, stdNum),
+		native("type", 1, stdType),
+
+		mathFunction("floor", math.Floor),
+		mathFunction("ceil", math.Ceil),
+		mathFunction("round", math.Round),
+		mathFunction("sqrt", math.Sqrt),
+		mathFunction("abs", math.Abs),
+		native("pow", 2, stdPow),
+		native("min", -1, extremum("min", math.Min)),
+		native("max", -1, extremum("max", math.Max)),
+
+		native("substr", 3, stdSubstr),
+		native("indexOf", 2, stdIndexOf),
+		stringFunction("upper", strings.ToUpper),
+		stringFunction("lower", strings.ToLower),
+		stringFunction("trim", strings.TrimSpace),
+		native("split", 2, stdSplit),
+		native("join", 2, stdJoin),
+	} {
+		env.Define(fn.name, fn)
+	}
+}
+
+func stdClock(_ *Interpreter, _ []Value) (Value, error) {
+	return float64(time.Now().UnixNano()) / float64(time.Second), nil
+}
+
+func stdLen(_ *Interpreter, args []Value) (Value, error) {
+	switch v := args[0].(type) {
+	case string:
+		return float64(utf8.RuneCountInString(v)), nil
+	case *List:
+		return float64(v.Len()), nil
+	case *Map:
+		return float64(v.Len()), nil
+	}
+	return nil, argumentError("len", "a string, list or map", args[0])
+}
+
+func stdStr(i *Interpreter, args []Value) (Value, error) {
+	if s, ok := args[0].(string); ok {
+		return s, nil
+	}
+	return i.stringify(args[0], i.line()), nil
+}
+
+// stdNum parses a string as a number, returning nil if it isn't one.
+func stdNum(_ *Interpreter, args []Value) (Value, error) {
+	switch v := args[0].(type) {
+	case float64:
+		return v, nil
+	case string:
+		n, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+		if err != nil {
+			return nil, nil
+		}
+		return n, nil
+	}
+	return nil, argumentError("num", "a string or number", args[0])
+}
+
+func stdType(_ *Interpreter, args []Value) (Value, error) {
+	return TypeOf(args[0]), nil
+}
+
+func mathFunction(name string, fn func(float64) float64) *NativeFunction {
+	return native(name, 1, func(_ *Interpreter, args []Value) (Value, error) {
+		n, err := numberArg(name, args[0])
+		if err != nil {
+			return nil, err
+		}
+		return fn(n), nil
+	})
+}
+
+func stdPow(_ *Interpreter, args []Value) (Value, error) {
+	base, err := numberArg("pow", args[0])
+	if err != nil {
+		return nil, err
+	}
+	exponent, err := numberArg("pow", args[1])
+	if err != nil {
+		return nil, err
+	}
+	return math.Pow(base, exponent), nil
+}
+
+func extremum(name string, pick func(a, b float64) float64) func(*Interpreter, []Value) (Value, error) {
+	return func(_ *Interpreter, args []Value) (Value, error) {
+		if len(args) == 0 {
+			return nil, fmt.Errorf("%s() expects at least 1 argument", name)
+		}
+		result, err := numberArg(name, args[0])
+		if err != nil {
+			return nil, err
+		}
+		for _, arg := range args[1:] {
+			n, err := numberArg(name, arg)
+			if err != nil {
+				return nil, err
+			}
+			result = pick(result, n)
+		}
+		return result, nil
+	}
+}
+
+func stringFunction(name string, fn func(string) string) *NativeFunction {
+	return native(name, 1, func(i *Interpreter, args []Value) (Value, error) {
+		s, err := stringArg(name, args[0])
+		if err != nil {
+			return nil, err
+		}
+		i.Allocate(len(s))
+		return fn(s), nil
+	})
+}
+
+// stdSubstr returns length characters of a string starting at start.
+func stdSubstr(i *Interpreter, args []Value) (Value, error) {
+	s, err := stringArg("substr", args[0])
+	if err != nil {
+		return nil, err
+	}
+	start, err := indexArg("substr", args[1])
+	if err != nil {
+		return nil, err
+	}
+	length, err := indexArg("substr", args[2])
+	if err != nil {
+		return nil, err
+	}
+
+	runes := []rune(s)
+	if start > len(runes) || length > len(runes)-start {
+		return nil, fmt.Errorf("substr() range %d..%d is out of bounds for a string of length %d", start, start+length, len(runes))
+	}
+	result := string(runes[start : start+length])
+	i.Allocate(len(result))
+	return result, nil
+}
+
+// stdIndexOf returns the character index of the first occurrence of a
+// substring, or -1.
+func stdIndexOf(_ *Interpreter, args []Value) (Value, error) {
+	s, err := stringArg("indexOf", args[0])
+	if err != nil {
+		return nil, err
+	}
+	sub, err := stringArg("indexOf", args[1])
+	if err != nil {
+		return nil, err
+	}
+
+	idx := strings.Index(s, sub)
+	if idx < 0 {
+		return -1.0, nil
+	}
+	return float64(utf8.RuneCountInString(s[:idx])), nil
+}
+
+func stdSplit(i *Interpreter, args []Value) (Value, error) {
+	s, err := stringArg("split", args[0])
+	if err != nil {
+		return nil, err
+	}
+	sep, err := stringArg("split", args[1])
+	if err != nil {
+		return nil, err
+	}
+
+	parts := strings.Split(s, sep)
+	i.Allocate(len(s) + len(parts)*listElementSize)
+	elements := make([]Value, len(parts))
+	for idx, part := range parts {
+		elements[idx] = part
+	}
+	return NewList(elements...), nil
+}
+
+func stdJoin(i *Interpreter, args []Value) (Value, error) {
+	list, ok := args[0].(*List)
+	if !ok {
+		return nil, argumentError("join", "a list", args[0])
+	}
+	sep, err := stringArg("join", args[1])
+	if err != nil {
+		return nil, err
+	}
+
+	w := i.valueWriter()
+	for idx, element := range list.Elements() {
+		if w.full() {
+			break
+		}
+		if idx > 0 {
+			w.write(sep)
+		}
+		w.value(element)
+	}
+	i.Allocate(w.Len())
+	return w.String(), nil
+}
+
+func numberArg(name string, arg Value) (float64, error) {
+	n, ok := arg.(float64)
+	if !ok {
+		return 0, argumentError(name, "a number", arg)
+	}
+	return n, nil
+}
+
+func indexArg(name string, arg Value) (int, error) {
+	n, ok := arg.(float64)
+	if !ok || n != math.Trunc(n) || n < 0 {
+		return 0, argumentError(name, "a non-negative integer", arg)
+	}
+	// Checked before converting, since converting a number too large for an
+	// int gives a meaningless one. No string or list is this long.
+	if n > math.MaxInt32 {
+		return 0, fmt.Errorf("%s() index %s is too large", name, Stringify(n))
+	}
+	return int(n), nil
+}
+
+func stringArg(name string, arg Value) (string, error) {
+	s, ok := arg.(string)
+	if !ok {
+		return "", argumentError(name, "a string", arg)
+	}
+	return s, nil
+}
+
+func argumentError(name, expected string, got Value) error {
+	return fmt.Errorf("%s() expects %s but got %s", name, expected, TypeOf(got))
+}
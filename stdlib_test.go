@@ -0,0 +1,112 @@
+// ABOUTME: Tests for the standard library of native functions
+// ABOUTME: Evaluates each built-in from Lox source, including argument errors
+package lox
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStdlib(t *testing.T) {
+	tests := []struct {
+		name     string
+		source   string
+		expected Value
+		errorMsg string
+	}{
+		// Conversion and introspection
+		{name: "len: string", source: `len("héllo")`, expected: 5.0},
+		{name: "len: list", source: `len(split("a,b,c", ","))`, expected: 3.0},
+		{name: "len: number", source: "len(12)", errorMsg: "[line 1] runtime error: len() expects a string, list or map but got number"},
+		{name: "str: number", source: "str(1.5) + str(2)", expected: "1.52"},
+		{name: "str: nil and booleans", source: `str(nil) + str(true)`, expected: "niltrue"},
+		{name: "str: list", source: `str(split("a b", " "))`, expected: `["a", "b"]`},
+		{name: "num: parses strings", source: `num(" 42.5 ") + 1`, expected: 43.5},
+		{name: "num: unparseable", source: `num("forty")`, expected: nil},
+		{name: "num: number", source: "num(7)", expected: 7.0},
+		{name: "num: boolean", source: "num(true)", errorMsg: "[line 1] runtime error: num() expects a string or number but got boolean"},
+		{name: "type: number", source: "type(1)", expected: "number"},
+		{name: "type: string", source: `type("")`, expected: "string"},
+		{name: "type: boolean", source: "type(false)", expected: "boolean"},
+		{name: "type: nil", source: "type(nil)", expected: "nil"},
+		{name: "type: function", source: "type(type)", expected: "function"},
+		{name: "type: list", source: `type(split("", ","))`, expected: "list"},
+
+		// Math
+		{name: "floor", source: "floor(2.7)", expected: 2.0},
+		{name: "ceil", source: "ceil(2.1)", expected: 3.0},
+		{name: "round", source: "round(2.5)", expected: 3.0},
+		{name: "sqrt", source: "sqrt(16)", expected: 4.0},
+		{name: "abs", source: "abs(-3)", expected: 3.0},
+		{name: "pow", source: "pow(2, 10)", expected: 1024.0},
+		{name: "min", source: "min(3, -1, 2)", expected: -1.0},
+		{name: "max", source: "max(3, -1, 2)", expected: 3.0},
+		{name: "max: single argument", source: "max(5)", expected: 5.0},
+		{name: "min: no arguments", source: "min()", errorMsg: "[line 1] runtime error: min() expects at least 1 argument"},
+		{name: "floor: string", source: `floor("2")`, errorMsg: "[line 1] runtime error: floor() expects a number but got string"},
+		{name: "pow: wrong arity", source: "pow(2)", errorMsg: "[line 1] runtime error: expected 2 arguments but got 1"},
+
+		// Strings
+		{name: "substr", source: `substr("glox rules", 5, 5)`, expected: "rules"},
+		{name: "substr: unicode", source: `substr("héllo", 1, 2)`, expected: "él"},
+		{name: "substr: out of bounds", source: `substr("abc", 2, 5)`, errorMsg: "[line 1] runtime error: substr() range 2..7 is out of bounds for a string of length 3"},
+		{name: "substr: fractional index", source: `substr("abc", 0.5, 1)`, errorMsg: "[line 1] runtime error: substr() expects a non-negative integer but got number"},
+		{name: "substr: fractional length", source: `substr("abc", 0, 1.5)`, errorMsg: "[line 1] runtime error: substr() expects a non-negative integer but got number"},
+		{name: "substr: start past the end", source: `substr("abc", 4, 0)`, errorMsg: "[line 1] runtime error: substr() range 4..4 is out of bounds for a string of length 3"},
+		{name: "substr: huge length", source: `substr("abc", 0, 100000000000000000000)`, errorMsg: "[line 1] runtime error: substr() index 100000000000000000000 is too large"},
+		{name: "substr: huge start", source: `substr("abc", 100000000000000000000, 1)`, errorMsg: "[line 1] runtime error: substr() index 100000000000000000000 is too large"},
+		{name: "substr: largest index", source: `substr("abc", 2147483647, 2147483647)`, errorMsg: "[line 1] runtime error: substr() range 2147483647..4294967294 is out of bounds for a string of length 3"},
+		{name: "indexOf", source: `indexOf("héllo", "l")`, expected: 2.0},
+		{name: "indexOf: missing", source: `indexOf("abc", "z")`, expected: -1.0},
+		{name: "upper", source: `upper("Lox")`, expected: "LOX"},
+		{name: "lower", source: `lower("Lox")`, expected: "lox"},
+		{name: "trim", source: `trim("  lox  ")`, expected: "lox"},
+		{name: "upper: number", source: "upper(1)", errorMsg: "[line 1] runtime error: upper() expects a string but got number"},
+		{name: "split and join", source: `join(split("a-b-c", "-"), "+")`, expected: "a+b+c"},
+		{name: "join: non-list", source: `join("abc", ",")`, errorMsg: "[line 1] runtime error: join() expects a list but got string"},
+
+		// Errors are reported at the call site's line
+		{name: "error line", source: "1 +\n\nsqrt(nil)", errorMsg: "[line 3] runtime error: sqrt() expects a number but got nil"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			asrt := assert.New(t)
+			result, err := NewVM().Eval(tt.source)
+
+			if tt.errorMsg != "" {
+				asrt.ErrorIs(err, ErrLoxRuntime)
+				asrt.EqualError(err, tt.errorMsg)
+				return
+			}
+			asrt.NoError(err)
+			asrt.Equal(tt.expected, result)
+		})
+	}
+}
+
+func TestStdlib_Clock(t *testing.T) {
+	asrt := assert.New(t)
+	result, err := NewVM().Eval("clock()")
+	asrt.NoError(err)
+	asrt.InDelta(float64(time.Now().Unix()), result, 5)
+}
+
+func TestStdlib_GlobalsShadowBuiltins(t *testing.T) {
+	asrt := assert.New(t)
+	vm := NewVM()
+	asrt.NoError(vm.SetGlobal("len", 3))
+
+	result, err := vm.Eval("len")
+	asrt.NoError(err)
+	asrt.Equal(3.0, result)
+	asrt.Equal([]string{"len"}, vm.Interpreter().Globals().Names())
+}
+
+func TestStdlib_MemoryLimit(t *testing.T) {
+	vm := NewVM(WithMemoryLimit(8))
+	_, err := vm.Eval(`upper("abcdefghij")`)
+	assert.ErrorIs(t, err, ErrMemoryLimitExceeded)
+}
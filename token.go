@@ -12,6 +12,8 @@ const (
 	RightParen
 	LeftBrace
 	RightBrace
+	LeftBracket
+	RightBracket
 	Comma
 	Dot
 	Minus
@@ -19,6 +21,7 @@ const (
 	Semicolon
 	Slash
 	Star
+	Colon
 
 	Bang
 	BangEqual
@@ -34,19 +37,24 @@ const (
 	Number
 
 	And
+	Catch
 	Class
 	Else
 	False
+	Finally
 	Fun
 	For
 	If
+	In
 	Nil
 	Or
 	Print
 	Return
 	Super
 	This
+	Throw
 	True
+	Try
 	Var
 	While
 
@@ -54,29 +62,42 @@ const (
 )
 
 var keywords = map[string]TokenType{
-	"and":    And,
-	"class":  Class,
-	"else":   Else,
-	"false":  False,
-	"fun":    Fun,
-	"for":    For,
-	"if":     If,
-	"nil":    Nil,
-	"or":     Or,
-	"print":  Print,
-	"return": Return,
-	"super":  Super,
-	"this":   This,
-	"true":   True,
-	"var":    Var,
-	"while":  While,
+	"and":     And,
+	"catch":   Catch,
+	"class":   Class,
+	"else":    Else,
+	"false":   False,
+	"finally": Finally,
+	"fun":     Fun,
+	"for":     For,
+	"if":      If,
+	"in":      In,
+	"nil":     Nil,
+	"or":      Or,
+	"print":   Print,
+	"return":  Return,
+	"super":   Super,
+	"this":    This,
+	"throw":   Throw,
+	"true":    True,
+	"try":     Try,
+	"var":     Var,
+	"while":   While,
 }
 
+// Token is a lexeme scanned from Lox source. Line is the line the token
+// ends on, which is only later than the one it starts on for a string
+// spanning lines. Offset is the byte offset of the token's first byte in the
+// source, and Column the 1-based column of that byte, counted in bytes.
+// Tokens made with NewToken rather than by a Scanner have a Column of 0,
+// and no known offset.
 type Token struct {
 	TokenType
 	Lexeme string
 	Object any
 	Line   int
+	Offset int
+	Column int
 }
 
 func NewToken(tokenType TokenType, lexeme string, object any, line int) Token {
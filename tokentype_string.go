@@ -12,46 +12,54 @@ func _() {
 	_ = x[RightParen-1]
 	_ = x[LeftBrace-2]
 	_ = x[RightBrace-3]
-	_ = x[Comma-4]
-	_ = x[Dot-5]
-	_ = x[Minus-6]
-	_ = x[Plus-7]
-	_ = x[Semicolon-8]
-	_ = x[Slash-9]
-	_ = x[Star-10]
-	_ = x[Bang-11]
-	_ = x[BangEqual-12]
-	_ = x[Equal-13]
-	_ = x[EqualEqual-14]
-	_ = x[Greater-15]
-	_ = x[GreaterEqual-16]
-	_ = x[Less-17]
-	_ = x[LessEqual-18]
-	_ = x[Identifier-19]
-	_ = x[String-20]
-	_ = x[Number-21]
-	_ = x[And-22]
-	_ = x[Class-23]
-	_ = x[Else-24]
-	_ = x[False-25]
-	_ = x[Fun-26]
-	_ = x[For-27]
-	_ = x[If-28]
-	_ = x[Nil-29]
-	_ = x[Or-30]
-	_ = x[Print-31]
-	_ = x[Return-32]
-	_ = x[Super-33]
-	_ = x[This-34]
-	_ = x[True-35]
-	_ = x[Var-36]
-	_ = x[While-37]
-	_ = x[EOF-38]
+	_ = x[LeftBracket-4]
+	_ = x[RightBracket-5]
+	_ = x[Comma-6]
+	_ = x[Dot-7]
+	_ = x[Minus-8]
+	_ = x[Plus-9]
+	_ = x[Semicolon-10]
+	_ = x[Slash-11]
+	_ = x[Star-12]
+	_ = x[Colon-13]
+	_ = x[Bang-14]
+	_ = x[BangEqual-15]
+	_ = x[Equal-16]
+	_ = x[EqualEqual-17]
+	_ = x[Greater-18]
+	_ = x[GreaterEqual-19]
+	_ = x[Less-20]
+	_ = x[LessEqual-21]
+	_ = x[Identifier-22]
+	_ = x[String-23]
+	_ = x[Number-24]
+	_ = x[And-25]
+	_ = x[Catch-26]
+	_ = x[Class-27]
+	_ = x[Else-28]
+	_ = x[False-29]
+	_ = x[Finally-30]
+	_ = x[Fun-31]
+	_ = x[For-32]
+	_ = x[If-33]
+	_ = x[In-34]
+	_ = x[Nil-35]
+	_ = x[Or-36]
+	_ = x[Print-37]
+	_ = x[Return-38]
+	_ = x[Super-39]
+	_ = x[This-40]
+	_ = x[Throw-41]
+	_ = x[True-42]
+	_ = x[Try-43]
+	_ = x[Var-44]
+	_ = x[While-45]
+	_ = x[EOF-46]
 }
 
-const _TokenType_name = "LeftParenRightParenLeftBraceRightBraceCommaDotMinusPlusSemicolonSlashStarBangBangEqualEqualEqualEqualGreaterGreaterEqualLessLessEqualIdentifierStringNumberAndClassElseFalseFunForIfNilOrPrintReturnSuperThisTrueVarWhileEOF"
+const _TokenType_name = "LeftParenRightParenLeftBraceRightBraceLeftBracketRightBracketCommaDotMinusPlusSemicolonSlashStarColonBangBangEqualEqualEqualEqualGreaterGreaterEqualLessLessEqualIdentifierStringNumberAndCatchClassElseFalseFinallyFunForIfInNilOrPrintReturnSuperThisThrowTrueTryVarWhileEOF"
 
-var _TokenType_index = [...]uint8{0, 9, 19, 28, 38, 43, 46, 51, 55, 64, 69, 73, 77, 86, 91, 101, 108, 120, 124, 133, 143, 149, 155, 158, 163, 167, 172, 175, 178, 180, 183, 185, 190, 196, 201, 205, 209, 212, 217, 220}
+var _TokenType_index = [...]uint16{0, 9, 19, 28, 38, 49, 61, 66, 69, 74, 78, 87, 92, 96, 101, 105, 114, 119, 129, 136, 148, 152, 161, 171, 177, 183, 186, 191, 196, 200, 205, 212, 215, 218, 220, 222, 225, 227, 232, 238, 243, 247, 252, 256, 259, 262, 267, 270}
 
 func (i TokenType) String() string {
 	idx := int(i) - 0
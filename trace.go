@@ -0,0 +1,116 @@
+package lox
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// WithTrace writes a trace of evaluation to w, showing the program as a
+// stack machine would run it. Each expression is a step that takes the
+// values of its operands from the top of the stack and pushes its result.
+// Statements that use values are steps too. Before each step, the trace
+// shows the stack and then the step with its source line:
+//
+//	       [ 1 ][ 2 ]
+//	1 Binary +
+//
+// Nothing in the trace depends on timing or addresses, so it is the same on
+// every run.
+func WithTrace(w io.Writer) Option {
+	return func(i *Interpreter) {
+		i.tracer = &tracer{w: w}
+	}
+}
+
+type tracer struct {
+	w     io.Writer
+	stack []Value
+}
+
+// mark returns the height of the stack, for unwind to return to.
+func (t *tracer) mark() int {
+	if t == nil {
+		return 0
+	}
+	return len(t.stack)
+}
+
+func (t *tracer) unwind(mark int) {
+	if t != nil && mark <= len(t.stack) {
+		t.stack = t.stack[:mark]
+	}
+}
+
+// expr traces e, whose operands are the values on the stack above mark, and
+// leaves its result in their place.
+func (t *tracer) expr(e Expr, mark int, result Value) {
+	if t == nil {
+		return
+	}
+	t.print(e)
+	t.unwind(mark)
+	t.stack = append(t.stack, result)
+}
+
+// stmt traces s, which uses up the value of its expression. A for-in loop
+// keeps its iterable on the stack until the loop ends instead.
+func (t *tracer) stmt(s Stmt) {
+	if t == nil {
+		return
+	}
+	t.print(s)
+	switch s := s.(type) {
+	case ForInStmt:
+		return
+	case VarStmt:
+		if s.initializer == nil {
+			return
+		}
+	}
+	t.unwind(len(t.stack) - 1)
+}
+
+func (t *tracer) print(n Node) {
+	var line strings.Builder
+	line.WriteString("          ")
+	if len(t.stack) == 0 {
+		line.WriteString("<empty>")
+	}
+	for _, v := range t.stack {
+		fmt.Fprintf(&line, "[ %s ]", quote(v))
+	}
+	fmt.Fprintln(t.w, line.String())
+	fmt.Fprintf(t.w, "%4d %s\n", instructionLine(n), describeStep(n))
+}
+
+// describeStep names the operation n performs, with any operand that isn't
+// on the stack.
+func describeStep(n Node) string {
+	name := nodeName(n)
+	switch n := n.(type) {
+	case Binary:
+		return name + " " + n.operator.Lexeme
+	case Unary:
+		return name + " " + n.operator.Lexeme
+	case Literal:
+		return name + " " + quote(n.literal)
+	case Variable:
+		return name + " " + n.name.Lexeme
+	case Assign:
+		return name + " " + n.name.Lexeme
+	case Get:
+		return name + " " + n.name.Lexeme
+	case Call:
+		return fmt.Sprintf("%s %d", name, len(n.arguments))
+	case ListLiteral:
+		return fmt.Sprintf("%s %d", name, len(n.elements))
+	case MapLiteral:
+		return fmt.Sprintf("%s %d", name, len(n.keys))
+	case VarStmt:
+		return name + " " + n.name.Lexeme
+	case ForInStmt:
+		return name + " " + n.name.Lexeme
+	}
+	return name
+}
@@ -0,0 +1,103 @@
+// ABOUTME: Tests for the interpreter's execution trace
+// ABOUTME: Compares traces of small programs against golden output
+package lox
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTrace(t *testing.T) {
+	tests := []struct {
+		name     string
+		source   string
+		expected string
+	}{
+		{
+			name:   "expression operands",
+			source: "var x = 2;\nprint x * (1 + 3);",
+			expected: `          <empty>
+   1 Literal 2
+          [ 2 ]
+   1 VarStmt x
+          <empty>
+   2 Variable x
+          [ 2 ]
+   2 Literal 1
+          [ 2 ][ 1 ]
+   2 Literal 3
+          [ 2 ][ 1 ][ 3 ]
+   2 Binary +
+          [ 2 ][ 4 ]
+   2 Group
+          [ 2 ][ 4 ]
+   2 Binary *
+          [ 8 ]
+   2 PrintStmt
+8
+`,
+		},
+		{
+			name:   "loop keeps its iterable",
+			source: "var y;\nfor s in [\"a\"] if (s) print s;",
+			expected: `          <empty>
+   1 VarStmt y
+          <empty>
+   2 Literal "a"
+          [ "a" ]
+   2 ListLiteral 1
+          [ ["a"] ]
+   2 ForInStmt s
+          [ ["a"] ]
+   2 Variable s
+          [ ["a"] ][ "a" ]
+   2 IfStmt
+          [ ["a"] ]
+   2 Variable s
+          [ ["a"] ][ "a" ]
+   2 PrintStmt
+a
+`,
+		},
+		{
+			name:   "caught error unwinds the stack",
+			source: "try { print 1 + -\"a\"; } catch (e) {}\nprint nil;",
+			expected: `          <empty>
+   1 Literal 1
+          [ 1 ]
+   1 Literal "a"
+          <empty>
+   2 Literal nil
+          [ nil ]
+   2 PrintStmt
+nil
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			_, err := NewInterpreter(WithStdout(&out), WithTrace(&out)).Execute(parseProgram(t, tt.source))
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, out.String())
+		})
+	}
+}
+
+func TestTrace_Deterministic(t *testing.T) {
+	statements := parseProgram(t, `var m = {"b": 1, "a": [true]}; print m["a"];`)
+
+	traces := make([]string, 3)
+	for idx := range traces {
+		var out strings.Builder
+		_, err := NewInterpreter(WithStdout(&out), WithTrace(&out)).Execute(statements)
+		assert.NoError(t, err)
+		traces[idx] = out.String()
+	}
+	assert.Equal(t, traces[0], traces[1])
+	assert.Equal(t, traces[0], traces[2])
+}
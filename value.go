@@ -0,0 +1,374 @@
+package lox
+
+import (
+	"cmp"
+	"fmt"
+	"math"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"unsafe"
+)
+
+// Value is a Lox runtime value: nil, bool, float64, string, *List, *Map or
+// a Callable.
+type Value = any
+
+// ToValue converts a Go value into a Lox value. All Go numeric types become
+// float64, and Go slices, arrays and maps become new Lists and Maps with
+// their elements converted in turn. A Go map's keys must convert to values
+// Lox can use as keys; they are added in sorted order, since Go maps have
+// none of their own. A slice or map that contains itself becomes a List or
+// Map that contains itself.
+func ToValue(v any) (Value, error) {
+	return toValue(v, map[any]Value{})
+}
+
+// sliceIdentity identifies a Go slice by the elements it refers to.
+type sliceIdentity struct {
+	data unsafe.Pointer
+	len  int
+	typ  reflect.Type
+}
+
+// toValue converts v as ToValue does. converted holds the Lists and Maps
+// made for the slices and maps being converted, so that one met again
+// inside itself is converted to the same value instead of forever.
+func toValue(v any, converted map[any]Value) (Value, error) {
+	switch v := v.(type) {
+	case nil, bool, float64, string, *List, *Map, Callable:
+		return v, nil
+	case float32:
+		return float64(v), nil
+	case int:
+		return float64(v), nil
+	case int8:
+		return float64(v), nil
+	case int16:
+		return float64(v), nil
+	case int32:
+		return float64(v), nil
+	case int64:
+		return float64(v), nil
+	case uint:
+		return float64(v), nil
+	case uint8:
+		return float64(v), nil
+	case uint16:
+		return float64(v), nil
+	case uint32:
+		return float64(v), nil
+	case uint64:
+		return float64(v), nil
+	}
+
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Array:
+		list := NewList(make([]Value, rv.Len())...)
+		if rv.Kind() == reflect.Slice && rv.Len() > 0 {
+			identity := sliceIdentity{data: rv.UnsafePointer(), len: rv.Len(), typ: rv.Type()}
+			if seen, ok := converted[identity]; ok {
+				return seen, nil
+			}
+			converted[identity] = list
+		}
+		for idx := range list.elements {
+			element, err := toValue(rv.Index(idx).Interface(), converted)
+			if err != nil {
+				return nil, fmt.Errorf("element %d: %w", idx, err)
+			}
+			list.elements[idx] = element
+		}
+		return list, nil
+	case reflect.Map:
+		m := NewMap()
+		if seen, ok := converted[rv.UnsafePointer()]; ok {
+			return seen, nil
+		}
+		converted[rv.UnsafePointer()] = m
+
+		type entry struct{ key, value Value }
+		entries := make([]entry, 0, rv.Len())
+		for it := rv.MapRange(); it.Next(); {
+			key, err := toValue(it.Key().Interface(), converted)
+			if err == nil {
+				err = checkKey(key)
+			}
+			if err != nil {
+				return nil, fmt.Errorf("key %v: %w", it.Key(), err)
+			}
+			value, err := toValue(it.Value().Interface(), converted)
+			if err != nil {
+				return nil, fmt.Errorf("key %v: %w", it.Key(), err)
+			}
+			entries = append(entries, entry{key, value})
+		}
+		slices.SortFunc(entries, func(a, b entry) int { return compareKeys(a.key, b.key) })
+		for _, e := range entries {
+			m.Set(e.key, e.value)
+		}
+		return m, nil
+	}
+	return nil, fmt.Errorf("cannot convert %T to a Lox value", v)
+}
+
+// compareKeys orders map keys converted from Go: by type, then by value.
+func compareKeys(a, b Value) int {
+	if c := strings.Compare(TypeOf(a), TypeOf(b)); c != 0 {
+		return c
+	}
+	switch a := a.(type) {
+	case float64:
+		return cmp.Compare(a, b.(float64))
+	case string:
+		return strings.Compare(a, b.(string))
+	case bool:
+		if a == b.(bool) {
+			return 0
+		} else if a {
+			return 1
+		}
+		return -1
+	}
+	return 0
+}
+
+// FromValue converts a Lox value into the Go type T. Numbers convert to any Go
+// numeric type as long as no precision is lost and the number is in range,
+// and Lists and Maps convert to Go slices and maps whose elements convert in
+// turn.
+func FromValue[T any](v Value) (T, error) {
+	var zero T
+	if t, ok := v.(T); ok {
+		return t, nil
+	}
+	if err := fromValue(v, reflect.ValueOf(&zero).Elem()); err != nil {
+		return zero, err
+	}
+	return zero, nil
+}
+
+// fromValue converts v into target, which must be settable and hold its
+// zero value. Nil converts to the zero value of any type that has nil as one.
+func fromValue(v Value, target reflect.Value) error {
+	if v == nil {
+		switch target.Kind() {
+		case reflect.Interface, reflect.Pointer, reflect.Slice, reflect.Map, reflect.Func:
+			return nil
+		}
+	} else if reflect.TypeOf(v).AssignableTo(target.Type()) {
+		target.Set(reflect.ValueOf(v))
+		return nil
+	}
+
+	switch v := v.(type) {
+	case float64:
+		return fromNumber(v, target)
+	case *List:
+		if target.Kind() != reflect.Slice {
+			break
+		}
+		converted := reflect.MakeSlice(target.Type(), v.Len(), v.Len())
+		for idx, element := range v.Elements() {
+			if err := fromValue(element, converted.Index(idx)); err != nil {
+				return fmt.Errorf("element %d: %w", idx, err)
+			}
+		}
+		target.Set(converted)
+		return nil
+	case *Map:
+		if target.Kind() != reflect.Map {
+			break
+		}
+		converted := reflect.MakeMapWithSize(target.Type(), v.Len())
+		for _, key := range v.Keys() {
+			k := reflect.New(target.Type().Key()).Elem()
+			if err := fromValue(key, k); err != nil {
+				return fmt.Errorf("key %s: %w", Stringify(key), err)
+			}
+			value, _ := v.Get(key)
+			e := reflect.New(target.Type().Elem()).Elem()
+			if err := fromValue(value, e); err != nil {
+				return fmt.Errorf("key %s: %w", Stringify(key), err)
+			}
+			converted.SetMapIndex(k, e)
+		}
+		target.Set(converted)
+		return nil
+	}
+	return fmt.Errorf("cannot convert %s to %s", TypeOf(v), target.Type())
+}
+
+// fromNumber converts n into the numeric target. The range is checked
+// before converting, since converting an out of range float64 to an integer
+// doesn't fail; it gives an arbitrary result.
+func fromNumber(n float64, target reflect.Value) error {
+	lost := fmt.Errorf("cannot convert %v to %s without losing precision", n, target.Type())
+	switch target.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		bits := target.Type().Bits()
+		if n != math.Trunc(n) || !(n >= math.Ldexp(-1, bits-1) && n < math.Ldexp(1, bits-1)) {
+			return lost
+		}
+		target.SetInt(int64(n))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		bits := target.Type().Bits()
+		if n != math.Trunc(n) || !(n >= 0 && n < math.Ldexp(1, bits)) {
+			return lost
+		}
+		target.SetUint(uint64(n))
+	case reflect.Float32:
+		if math.Abs(n) > math.MaxFloat32 && !math.IsInf(n, 0) {
+			return lost
+		}
+		target.SetFloat(n)
+	case reflect.Float64:
+		target.SetFloat(n)
+	default:
+		return fmt.Errorf("cannot convert number to %s", target.Type())
+	}
+	return nil
+}
+
+// TypeOf names the Lox type of a value.
+func TypeOf(v Value) string {
+	switch v.(type) {
+	case nil:
+		return "nil"
+	case bool:
+		return "boolean"
+	case float64:
+		return "number"
+	case string:
+		return "string"
+	case *List:
+		return "list"
+	case *Map:
+		return "map"
+	case *ErrorValue:
+		return "error"
+	case Callable:
+		return "function"
+	}
+	return fmt.Sprintf("%T", v)
+}
+
+// Stringify formats a value the way Lox source would write it. A list or map
+// that contains itself is written as [...] or {...} where it appears inside
+// itself.
+func Stringify(v Value) string {
+	var w valueWriter
+	w.value(v)
+	return w.String()
+}
+
+// quote formats a value nested inside a collection, where strings are quoted
+// so they can be told apart from other values.
+func quote(v Value) string {
+	var w valueWriter
+	w.quoted(v)
+	return w.String()
+}
+
+// valueWriter formats values for Stringify and quote.
+type valueWriter struct {
+	strings.Builder
+
+	// writing holds the lists and maps being written, so that one met again
+	// inside itself can be written as [...] or {...} instead of forever.
+	writing map[Value]bool
+
+	// limit, if positive, is the most bytes to write. Writing stops once it
+	// is reached, however much of the value is left.
+	limit int
+}
+
+// write writes as much of s as the limit allows.
+func (w *valueWriter) write(s string) {
+	if w.limit > 0 && w.Len()+len(s) > w.limit {
+		s = s[:max(w.limit-w.Len(), 0)]
+	}
+	w.WriteString(s)
+}
+
+// full reports whether the limit has been reached.
+func (w *valueWriter) full() bool {
+	return w.limit > 0 && w.Len() >= w.limit
+}
+
+func (w *valueWriter) value(v Value) {
+	if w.full() {
+		return
+	}
+	switch v := v.(type) {
+	case nil:
+		w.write("nil")
+	case float64:
+		w.write(strconv.FormatFloat(v, 'f', -1, 64))
+	case *List:
+		if !w.enter(v) {
+			w.write("[...]")
+			return
+		}
+		defer delete(w.writing, v)
+		w.write("[")
+		for idx, element := range v.elements {
+			if w.full() {
+				return
+			}
+			if idx > 0 {
+				w.write(", ")
+			}
+			w.quoted(element)
+		}
+		w.write("]")
+	case *Map:
+		if !w.enter(v) {
+			w.write("{...}")
+			return
+		}
+		defer delete(w.writing, v)
+		w.write("{")
+		for idx, key := range v.keys {
+			if w.full() {
+				return
+			}
+			if idx > 0 {
+				w.write(", ")
+			}
+			w.quoted(key)
+			w.write(": ")
+			w.quoted(v.values[key])
+		}
+		w.write("}")
+	case fmt.Stringer:
+		w.write(v.String())
+	default:
+		w.write(fmt.Sprint(v))
+	}
+}
+
+func (w *valueWriter) quoted(v Value) {
+	if s, ok := v.(string); ok {
+		w.write(`"`)
+		w.write(s)
+		w.write(`"`)
+		return
+	}
+	w.value(v)
+}
+
+// enter records that collection is being written, and reports false if it
+// already was.
+func (w *valueWriter) enter(collection Value) bool {
+	if w.writing[collection] {
+		return false
+	}
+	if w.writing == nil {
+		w.writing = map[Value]bool{}
+	}
+	w.writing[collection] = true
+	return true
+}
@@ -0,0 +1,90 @@
+package lox
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+)
+
+// VM runs Lox source for programs that embed glox. Globals defined with
+// SetGlobal and RegisterFunc persist across calls to Eval and RunFile.
+type VM struct {
+	interpreter *Interpreter
+}
+
+func NewVM(opts ...Option) *VM {
+	return &VM{interpreter: NewInterpreter(opts...)}
+}
+
+// Eval scans, parses and evaluates source, returning the resulting value.
+func (vm *VM) Eval(source string) (Value, error) {
+	return vm.EvalContext(context.Background(), source)
+}
+
+// EvalContext is Eval, stopping early if ctx is done.
+func (vm *VM) EvalContext(ctx context.Context, source string) (Value, error) {
+	scanner := NewScanner(source)
+	tokens, err := scanner.ScanTokens()
+	if err != nil {
+		return nil, err
+	}
+
+	parser := NewParser(tokens)
+	statements, err := parser.ParseProgram()
+	if err != nil {
+		return nil, err
+	}
+
+	return vm.interpreter.ExecuteContext(ctx, statements)
+}
+
+// RunFile evaluates the Lox program in the file at path, which holds either
+// source or a program compiled with WriteCompiled.
+func (vm *VM) RunFile(path string) (Value, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if IsCompiled(data) {
+		statements, err := ReadCompiled(bytes.NewReader(data))
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		return vm.interpreter.Execute(statements)
+	}
+	return vm.Eval(string(data))
+}
+
+// SetGlobal defines a global variable, converting value with ToValue.
+func (vm *VM) SetGlobal(name string, value any) error {
+	v, err := ToValue(value)
+	if err != nil {
+		return fmt.Errorf("global %s: %w", name, err)
+	}
+	vm.interpreter.globals.Define(name, v)
+	return nil
+}
+
+// Global returns the value of a global variable.
+func (vm *VM) Global(name string) (Value, bool) {
+	return vm.interpreter.globals.Lookup(name)
+}
+
+// RegisterFunc defines a global native function. An arity of -1 accepts any
+// number of arguments. Results are converted with ToValue, and errors returned
+// by fn become runtime errors reported at the line of the call.
+func (vm *VM) RegisterFunc(name string, arity int, fn func(args []Value) (Value, error)) {
+	vm.interpreter.globals.Define(name, NewNativeFunction(name, arity, func(args []Value) (Value, error) {
+		result, err := fn(args)
+		if err != nil {
+			return nil, err
+		}
+		return ToValue(result)
+	}))
+}
+
+// Interpreter exposes the interpreter backing the VM, e.g. to install a Hook.
+func (vm *VM) Interpreter() *Interpreter {
+	return vm.interpreter
+}
@@ -0,0 +1,270 @@
+// ABOUTME: Tests for the embedding API that evaluates Lox source from Go
+// ABOUTME: Covers host globals, native functions and Go/Lox value conversion
+package lox
+
+import (
+	"bytes"
+	"errors"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVM_Eval(t *testing.T) {
+	tests := []struct {
+		name     string
+		source   string
+		expected Value
+		wantErr  error
+	}{
+		{name: "arithmetic", source: "(1 + 2) * limit", expected: 30.0},
+		{name: "string global", source: `greeting + ", world"`, expected: "hello, world"},
+		{name: "native function", source: "double(limit) == 20", expected: true},
+		{name: "variadic native", source: "count(1, 2, 3)", expected: 3.0},
+		{name: "native error", source: "double(greeting)", wantErr: ErrLoxRuntime},
+		{name: "syntax error", source: "(1 +", wantErr: ErrLoxSyntax},
+		{name: "undefined global", source: "missing", wantErr: ErrLoxRuntime},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			asrt := assert.New(t)
+			vm := NewVM()
+			asrt.NoError(vm.SetGlobal("limit", 10))
+			asrt.NoError(vm.SetGlobal("greeting", "hello"))
+			vm.RegisterFunc("double", 1, func(args []Value) (Value, error) {
+				n, err := FromValue[float64](args[0])
+				if err != nil {
+					return nil, err
+				}
+				return n * 2, nil
+			})
+			vm.RegisterFunc("count", -1, func(args []Value) (Value, error) {
+				return len(args), nil
+			})
+
+			result, err := vm.Eval(tt.source)
+			if tt.wantErr != nil {
+				asrt.ErrorIs(err, tt.wantErr)
+				return
+			}
+			asrt.NoError(err)
+			asrt.Equal(tt.expected, result)
+		})
+	}
+}
+
+func TestVM_NativeErrorLine(t *testing.T) {
+	vm := NewVM()
+	vm.RegisterFunc("fail", 0, func(args []Value) (Value, error) {
+		return nil, errors.New("rule rejected")
+	})
+
+	_, err := vm.Eval("1 +\n\nfail()")
+	assert.EqualError(t, err, "[line 3] runtime error: rule rejected")
+}
+
+func TestVM_RunFile(t *testing.T) {
+	asrt := assert.New(t)
+	path := filepath.Join(t.TempDir(), "rule.lox")
+	asrt.NoError(os.WriteFile(path, []byte("threshold > 5"), 0o644))
+
+	vm := NewVM()
+	asrt.NoError(vm.SetGlobal("threshold", uint8(7)))
+	result, err := vm.RunFile(path)
+	asrt.NoError(err)
+	asrt.Equal(true, result)
+
+	_, err = vm.RunFile(filepath.Join(t.TempDir(), "missing.lox"))
+	asrt.ErrorIs(err, os.ErrNotExist)
+}
+
+func TestVM_RunCompiledFile(t *testing.T) {
+	asrt := assert.New(t)
+	var compiled bytes.Buffer
+	asrt.NoError(WriteCompiled(&compiled, parseProgram(t, "threshold > 2 * 3")))
+	path := filepath.Join(t.TempDir(), "rule.loxc")
+	asrt.NoError(os.WriteFile(path, compiled.Bytes(), 0o644))
+
+	vm := NewVM()
+	asrt.NoError(vm.SetGlobal("threshold", 7))
+	result, err := vm.RunFile(path)
+	asrt.NoError(err)
+	asrt.Equal(true, result)
+
+	stale := compiled.Bytes()
+	stale[4]++
+	asrt.NoError(os.WriteFile(path, stale, 0o644))
+	_, err = vm.RunFile(path)
+	asrt.ErrorIs(err, ErrCompiledVersion)
+	asrt.ErrorContains(err, path+": unsupported compiled Lox version")
+}
+
+func TestVM_SetGlobalRejectsUnsupportedTypes(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    any
+		expected string
+	}{
+		{name: "struct", value: struct{}{}, expected: "global config: cannot convert struct {} to a Lox value"},
+		{name: "list element", value: []any{1, struct{}{}}, expected: "global config: element 1: cannot convert struct {} to a Lox value"},
+		{name: "map key", value: map[[2]int]int{{1, 2}: 3}, expected: "global config: key [1 2]: map keys must be nil, booleans, numbers or strings, got list"},
+		{name: "NaN map key", value: map[float64]int{math.NaN(): 1}, expected: "global config: key NaN: map key can't be NaN"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := NewVM().SetGlobal("config", tt.value)
+			assert.EqualError(t, err, tt.expected)
+		})
+	}
+}
+
+func TestVM_SetGlobalCollections(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    any
+		source   string
+		expected Value
+	}{
+		{name: "slice", value: []int{1, 2}, source: "xs[0] + xs[1]", expected: 3.0},
+		{name: "array", value: [2]string{"a", "b"}, source: "xs[1]", expected: "b"},
+		{name: "nested", value: [][]float64{{1}, {2, 3}}, source: "xs[1].len()", expected: 2.0},
+		{name: "map", value: map[string]int{"b": 2, "a": 1}, source: `xs["a"] + xs["b"]`, expected: 3.0},
+		{name: "map keys in order", value: map[string]bool{"b": true, "a": false, "c": true}, source: "str(xs.keys())", expected: `["a", "b", "c"]`},
+		{name: "map of slices", value: map[int][]int{1: {2}}, source: "xs[1][0]", expected: 2.0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			asrt := assert.New(t)
+			vm := NewVM()
+			asrt.NoError(vm.SetGlobal("xs", tt.value))
+			result, err := vm.Eval(tt.source)
+			asrt.NoError(err)
+			asrt.Equal(tt.expected, result)
+		})
+	}
+}
+
+func TestToValue_ContainsItself(t *testing.T) {
+	asrt := assert.New(t)
+
+	xs := []any{1, nil}
+	xs[1] = xs
+	value, err := ToValue(xs)
+	asrt.NoError(err)
+	list := value.(*List)
+	asrt.Same(list, list.Elements()[1])
+	asrt.Equal("[1, [...]]", Stringify(list))
+
+	m := map[string]any{"a": 1}
+	m["self"] = m
+	value, err = ToValue(m)
+	asrt.NoError(err)
+	self, _ := value.(*Map).Get("self")
+	asrt.Same(value, self)
+
+	shared := []any{1}
+	value, err = ToValue([]any{shared, shared, []any{}, []any{}})
+	asrt.NoError(err)
+	elements := value.(*List).Elements()
+	asrt.Same(elements[0], elements[1], "a slice is converted once")
+	asrt.NotSame(elements[2], elements[3], "empty slices are never shared")
+}
+
+func TestFromValue(t *testing.T) {
+	asrt := assert.New(t)
+
+	n, err := FromValue[int](3.0)
+	asrt.NoError(err)
+	asrt.Equal(3, n)
+
+	_, err = FromValue[int](3.5)
+	asrt.Error(err)
+
+	_, err = FromValue[uint8](300.0)
+	asrt.Error(err)
+
+	f, err := FromValue[float32](1.5)
+	asrt.NoError(err)
+	asrt.Equal(float32(1.5), f)
+
+	s, err := FromValue[string]("lox")
+	asrt.NoError(err)
+	asrt.Equal("lox", s)
+
+	_, err = FromValue[bool]("lox")
+	asrt.EqualError(err, "cannot convert string to bool")
+}
+
+func TestFromValue_Range(t *testing.T) {
+	tests := []struct {
+		name    string
+		convert func() (any, error)
+	}{
+		{name: "1e20 to int64", convert: func() (any, error) { return FromValue[int64](1e20) }},
+		{name: "-1e20 to int64", convert: func() (any, error) { return FromValue[int64](-1e20) }},
+		{name: "2^63 to int64", convert: func() (any, error) { return FromValue[int64](math.Ldexp(1, 63)) }},
+		{name: "1e20 to int", convert: func() (any, error) { return FromValue[int](1e20) }},
+		{name: "NaN to int", convert: func() (any, error) { return FromValue[int](math.NaN()) }},
+		{name: "+Inf to int", convert: func() (any, error) { return FromValue[int](math.Inf(1)) }},
+		{name: "-Inf to int", convert: func() (any, error) { return FromValue[int](math.Inf(-1)) }},
+		{name: "1e20 to uint64", convert: func() (any, error) { return FromValue[uint64](1e20) }},
+		{name: "-1e20 to uint64", convert: func() (any, error) { return FromValue[uint64](-1e20) }},
+		{name: "-1 to uint", convert: func() (any, error) { return FromValue[uint](-1.0) }},
+		{name: "NaN to uint", convert: func() (any, error) { return FromValue[uint](math.NaN()) }},
+		{name: "+Inf to uint", convert: func() (any, error) { return FromValue[uint](math.Inf(1)) }},
+		{name: "-Inf to uint", convert: func() (any, error) { return FromValue[uint](math.Inf(-1)) }},
+		{name: "1e300 to float32", convert: func() (any, error) { return FromValue[float32](1e300) }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.convert()
+			assert.ErrorContains(t, err, "without losing precision")
+		})
+	}
+
+	asrt := assert.New(t)
+	minimum, err := FromValue[int64](-math.Ldexp(1, 63))
+	asrt.NoError(err)
+	asrt.Equal(int64(math.MinInt64), minimum)
+	maximum, err := FromValue[uint8](255.0)
+	asrt.NoError(err)
+	asrt.Equal(uint8(255), maximum)
+	inf, err := FromValue[float32](math.Inf(1))
+	asrt.NoError(err)
+	asrt.True(math.IsInf(float64(inf), 1))
+}
+
+func TestFromValue_Collections(t *testing.T) {
+	asrt := assert.New(t)
+
+	xs, err := FromValue[[]int](NewList(1.0, 2.0))
+	asrt.NoError(err)
+	asrt.Equal([]int{1, 2}, xs)
+
+	anything, err := FromValue[[]any](NewList(1.0, "a", nil))
+	asrt.NoError(err)
+	asrt.Equal([]any{1.0, "a", nil}, anything)
+
+	m := NewMap()
+	m.Set("a", NewList(1.0))
+	m.Set("b", NewList())
+	nested, err := FromValue[map[string][]uint8](m)
+	asrt.NoError(err)
+	asrt.Equal(map[string][]uint8{"a": {1}, "b": {}}, nested)
+
+	_, err = FromValue[[]int](NewList(1.0, 1.5))
+	asrt.EqualError(err, "element 1: cannot convert 1.5 to int without losing precision")
+
+	_, err = FromValue[map[int]string](m)
+	asrt.EqualError(err, "key a: cannot convert string to int")
+
+	_, err = FromValue[[]int](m)
+	asrt.EqualError(err, "cannot convert map to []int")
+}
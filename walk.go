@@ -0,0 +1,237 @@
+package lox
+
+import "fmt"
+
+// Walk traverses the tree rooted at node in depth-first order. It calls f for
+// each node, and descends into the node's children only if f returns true.
+func Walk(node Node, f func(Node) bool) {
+	if node == nil || !f(node) {
+		return
+	}
+	for _, child := range children(node) {
+		Walk(child, f)
+	}
+}
+
+// Inspect traverses the tree rooted at node like go/ast.Inspect: it calls
+// f(node), and if that returns true, inspects each child and then calls
+// f(nil) to mark the end of node's children.
+func Inspect(node Node, f func(Node) bool) {
+	if node == nil || !f(node) {
+		return
+	}
+	for _, child := range children(node) {
+		Inspect(child, f)
+	}
+	f(nil)
+}
+
+// Rewrite rebuilds the tree rooted at node from the bottom up. Each node's
+// children are rewritten first, then f is called with a copy of the node
+// holding the new children, and its result takes the node's place.
+//
+// f must return an Expr where an expression is expected and a Stmt where a
+// statement is; Rewrite panics otherwise. Returning nil for a statement in a
+// block, try statement or program removes it, as it does for an else branch.
+// A removed loop body or then branch is replaced by an empty block.
+func Rewrite(node Node, f func(Node) Node) Node {
+	if node == nil {
+		return nil
+	}
+	return f(rewriteChildren(node, f))
+}
+
+// children lists the nodes directly under n, in source order.
+func children(n Node) []Node {
+	var nodes []Node
+	add := func(exprs ...Expr) {
+		for _, e := range exprs {
+			if e != nil {
+				nodes = append(nodes, e)
+			}
+		}
+	}
+	addStmts := func(stmts []Stmt) {
+		for _, s := range stmts {
+			nodes = append(nodes, s)
+		}
+	}
+
+	switch n := n.(type) {
+	case Binary:
+		add(n.left, n.right)
+	case Unary:
+		add(n.right)
+	case Group:
+		add(n.expr)
+	case Literal, Variable:
+	case Call:
+		add(n.callee)
+		add(n.arguments...)
+	case Assign:
+		add(n.value)
+	case ListLiteral:
+		add(n.elements...)
+	case MapLiteral:
+		for idx, key := range n.keys {
+			add(key, n.values[idx])
+		}
+	case Index:
+		add(n.object, n.index)
+	case SetIndex:
+		add(n.object, n.index, n.value)
+	case Get:
+		add(n.object)
+	case ExpressionStmt:
+		add(n.expr)
+	case PrintStmt:
+		add(n.expr)
+	case VarStmt:
+		add(n.initializer)
+	case BlockStmt:
+		addStmts(n.statements)
+	case ForInStmt:
+		add(n.iterable)
+		nodes = append(nodes, n.body)
+	case IfStmt:
+		add(n.condition)
+		nodes = append(nodes, n.thenBranch)
+		if n.elseBranch != nil {
+			nodes = append(nodes, n.elseBranch)
+		}
+	case ThrowStmt:
+		add(n.value)
+	case TryStmt:
+		addStmts(n.body)
+		addStmts(n.catchBody)
+		addStmts(n.finallyBody)
+	}
+	return nodes
+}
+
+// rewriteChildren returns a copy of n with each child replaced by the result
+// of rewriting it.
+func rewriteChildren(n Node, f func(Node) Node) Node {
+	expr := func(e Expr) Expr {
+		if e == nil {
+			return nil
+		}
+		rewritten := Rewrite(e, f)
+		result, ok := rewritten.(Expr)
+		if !ok {
+			panic(fmt.Sprintf("lox: Rewrite replaced expression %T with %T", e, rewritten))
+		}
+		return result
+	}
+	exprs := func(es []Expr) []Expr {
+		result := make([]Expr, len(es))
+		for idx, e := range es {
+			result[idx] = expr(e)
+		}
+		return result
+	}
+	optionalStmt := func(s Stmt) Stmt {
+		rewritten := Rewrite(s, f)
+		if rewritten == nil {
+			return nil
+		}
+		result, ok := rewritten.(Stmt)
+		if !ok {
+			panic(fmt.Sprintf("lox: Rewrite replaced statement %T with %T", s, rewritten))
+		}
+		return result
+	}
+	// A statement that must be present, such as a loop body, becomes an
+	// empty block when it is removed.
+	stmt := func(s Stmt) Stmt {
+		if result := optionalStmt(s); result != nil {
+			return result
+		}
+		return WithOrigin(BlockStmt{}, s).(Stmt)
+	}
+
+	switch n := n.(type) {
+	case Binary:
+		n.left, n.right = expr(n.left), expr(n.right)
+		return n
+	case Unary:
+		n.right = expr(n.right)
+		return n
+	case Group:
+		n.expr = expr(n.expr)
+		return n
+	case Call:
+		n.callee, n.arguments = expr(n.callee), exprs(n.arguments)
+		return n
+	case Assign:
+		n.value = expr(n.value)
+		return n
+	case ListLiteral:
+		n.elements = exprs(n.elements)
+		return n
+	case MapLiteral:
+		n.keys, n.values = exprs(n.keys), exprs(n.values)
+		return n
+	case Index:
+		n.object, n.index = expr(n.object), expr(n.index)
+		return n
+	case SetIndex:
+		n.object, n.index, n.value = expr(n.object), expr(n.index), expr(n.value)
+		return n
+	case Get:
+		n.object = expr(n.object)
+		return n
+	case ExpressionStmt:
+		n.expr = expr(n.expr)
+		return n
+	case PrintStmt:
+		n.expr = expr(n.expr)
+		return n
+	case VarStmt:
+		n.initializer = expr(n.initializer)
+		return n
+	case BlockStmt:
+		n.statements = RewriteProgram(n.statements, f)
+		return n
+	case ForInStmt:
+		n.iterable, n.body = expr(n.iterable), stmt(n.body)
+		return n
+	case IfStmt:
+		n.condition, n.thenBranch = expr(n.condition), stmt(n.thenBranch)
+		if n.elseBranch != nil {
+			n.elseBranch = optionalStmt(n.elseBranch)
+		}
+		return n
+	case ThrowStmt:
+		n.value = expr(n.value)
+		return n
+	case TryStmt:
+		n.body = RewriteProgram(n.body, f)
+		if n.catchBody != nil {
+			n.catchBody = RewriteProgram(n.catchBody, f)
+		}
+		if n.finallyBody != nil {
+			n.finallyBody = RewriteProgram(n.finallyBody, f)
+		}
+		return n
+	}
+	return n
+}
+
+// RewriteProgram rewrites each statement with Rewrite, dropping any that f
+// replaces with nil.
+func RewriteProgram(statements []Stmt, f func(Node) Node) []Stmt {
+	result := make([]Stmt, 0, len(statements))
+	for _, s := range statements {
+		rewritten := Rewrite(s, f)
+		if rewritten == nil {
+			continue
+		}
+		stmt, ok := rewritten.(Stmt)
+		if !ok {
+			panic(fmt.Sprintf("lox: Rewrite replaced statement %T with %T", s, rewritten))
+		}
+		result = append(result, stmt)
+	}
+	return result
+}
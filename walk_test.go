@@ -0,0 +1,175 @@
+// ABOUTME: Tests for the generic AST traversal helpers Walk, Inspect and Rewrite
+// ABOUTME: Runs small analyses and rewrites over parsed programs
+package lox
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const walkSource = `var xs = [1, "a", {"b": -2}];
+for x in xs { print x + "c"; }
+try { throw f(xs[0], xs.len()); } catch (e) { xs[1] = e; } finally { print "done"; }`
+
+func TestWalk_FindStringLiterals(t *testing.T) {
+	var found []string
+	for _, stmt := range parseProgram(t, walkSource) {
+		Walk(stmt, func(n Node) bool {
+			if lit, ok := n.(Literal); ok {
+				if s, ok := lit.Value().(string); ok {
+					found = append(found, s)
+				}
+			}
+			return true
+		})
+	}
+	assert.Equal(t, []string{"a", "b", "c", "done"}, found)
+}
+
+func TestWalk_CountNodes(t *testing.T) {
+	counts := map[string]int{}
+	for _, stmt := range parseProgram(t, walkSource) {
+		Walk(stmt, func(n Node) bool {
+			counts[strings.TrimPrefix(fmt.Sprintf("%T", n), "lox.")]++
+			return true
+		})
+	}
+	assert.Equal(t, map[string]int{
+		"VarStmt": 1, "ListLiteral": 1, "MapLiteral": 1, "Literal": 8, "Unary": 1,
+		"ForInStmt": 1, "BlockStmt": 1, "PrintStmt": 2, "Binary": 1, "Variable": 7,
+		"TryStmt": 1, "ThrowStmt": 1, "Call": 2, "Index": 1, "Get": 1,
+		"ExpressionStmt": 1, "SetIndex": 1,
+	}, counts)
+}
+
+func TestWalk_SkipsChildren(t *testing.T) {
+	var visited []string
+	for _, stmt := range parseProgram(t, "f(1 + 2, 3);") {
+		Walk(stmt, func(n Node) bool {
+			visited = append(visited, printNode(n))
+			_, isBinary := n.(Binary)
+			return !isBinary
+		})
+	}
+	assert.Equal(t, []string{"(; (call f (+ 1 2) 3))", "(call f (+ 1 2) 3)", "f", "(+ 1 2)", "3"}, visited)
+}
+
+func TestInspect(t *testing.T) {
+	var events []string
+	Inspect(parseSource(t, "-(1 + x)"), func(n Node) bool {
+		if n == nil {
+			events = append(events, "end")
+			return false
+		}
+		events = append(events, printNode(n))
+		return true
+	})
+	assert.Equal(t, []string{
+		"(- (group (+ 1 x)))",
+		"(group (+ 1 x))",
+		"(+ 1 x)",
+		"1", "end",
+		"x", "end",
+		"end", "end", "end",
+	}, events)
+}
+
+func TestRewrite(t *testing.T) {
+	tests := []struct {
+		name     string
+		source   string
+		rewrite  func(Node) Node
+		expected []string
+	}{
+		{
+			name:   "rename variables",
+			source: "var x = 1; for y in [x] { print y; }",
+			rewrite: func(n Node) Node {
+				if v, ok := n.(Variable); ok {
+					name := v.Name()
+					name.Lexeme = strings.ToUpper(name.Lexeme)
+					return NewVariable(name)
+				}
+				return n
+			},
+			expected: []string{"(var x 1)", "(for y (list X) (block (print Y)))"},
+		},
+		{
+			name:   "children are rewritten before parents",
+			source: "1 + 2 * 3",
+			rewrite: func(n Node) Node {
+				if b, ok := n.(Binary); ok {
+					return NewGroup(b)
+				}
+				return n
+			},
+			expected: []string{"(; (group (+ 1 (group (* 2 3)))))"},
+		},
+		{
+			name:   "remove print statements",
+			source: `print 1; { print 2; x; } try { print 3; } catch (e) { print e; } finally { print 4; }`,
+			rewrite: func(n Node) Node {
+				if _, ok := n.(PrintStmt); ok {
+					return nil
+				}
+				return n
+			},
+			expected: []string{"(block (; x))", "(try (block) (catch e (block)) (finally (block)))"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			statements := RewriteProgram(parseProgram(t, tt.source), tt.rewrite)
+			actual := []string{}
+			for _, stmt := range statements {
+				actual = append(actual, printStmt(stmt))
+			}
+			assert.Equal(t, tt.expected, actual)
+		})
+	}
+}
+
+func TestRewrite_RemovedBodyKeepsItsSpan(t *testing.T) {
+	statements := RewriteProgram(parseProgram(t, "for x in xs\n\nprint x;"), func(n Node) Node {
+		if _, ok := n.(PrintStmt); ok {
+			return nil
+		}
+		return n
+	})
+	body := statements[0].(ForInStmt).Body()
+	assert.Equal(t, "(block)", printStmt(body))
+	assert.Equal(t, Origin{Pos: Position{Line: 3, Column: 1, Offset: 13}, End: Position{Line: 3, Column: 8, Offset: 20}, Line: 3}, OriginOf(body))
+}
+
+func TestRewrite_LeavesOriginalUnchanged(t *testing.T) {
+	original := parseSource(t, "[1, 2]")
+	Rewrite(original, func(n Node) Node {
+		if _, ok := n.(Literal); ok {
+			return NewLiteral(0.0, Token{})
+		}
+		return n
+	})
+	assert.Equal(t, "(list 1 2)", printExpr(original))
+}
+
+func TestRewrite_PanicsOnWrongNodeKind(t *testing.T) {
+	assert.PanicsWithValue(t, "lox: Rewrite replaced expression lox.Literal with lox.ExpressionStmt", func() {
+		Rewrite(parseSource(t, "-1"), func(n Node) Node {
+			if lit, ok := n.(Literal); ok {
+				return ExpressionStmt{expr: lit}
+			}
+			return n
+		})
+	})
+}
+
+func printNode(n Node) string {
+	if stmt, ok := n.(Stmt); ok {
+		return printStmt(stmt)
+	}
+	return printExpr(n.(Expr))
+}